@@ -0,0 +1,72 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Append inserts elem, which is expected to be greater than all
+// elements in the tree, as with time-ordered ingest. Runs of appended
+// elements are collected by the transaction with a single call to
+// Compare each, and are built into a balanced tree that is joined onto
+// the right spine of the tree once the run ends, so the cost of copying
+// and rebalancing is amortized over the run. A run ends with the next
+// operation on the transaction other than Append. If elem is not
+// greater than the maximum, Append falls back to Insert, and while
+// inserts are buffered it is the same as BufferInsert.
+func (t *Txn) Append(elem Element) {
+	if len(t.buffered) > 0 {
+		t.BufferInsert(elem)
+		return
+	}
+	if !t.collect(elem) {
+		t.Insert(elem)
+	}
+}
+
+// collect collects elem if it is greater than all elements in the tree
+// and reports whether it did.
+func (t *Txn) collect(elem Element) bool {
+	t.own()
+	defer t.leave()
+
+	var max Element
+	if n := len(t.appends); n > 0 {
+		max = t.appends[n-1]
+	} else if t.tree.root != nil {
+		max = t.tree.root.max().elem
+	}
+	if max != nil && elem.Compare(max) <= 0 {
+		return false
+	}
+
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	var p *probe
+	if t.probe() != nil {
+		p = &probe{compares: 1}
+	}
+	t.account(opInsert, p)
+	t.appends = append(t.appends, elem)
+	t.bloom.insert(elem)
+	t.record(ChangeInsert, elem)
+	t.tree.size++
+	return true
+}
+
+// flush joins the elements collected by Append onto the tree and merges
+// the elements collected by BufferInsert into it.
+func (t *Txn) flush() {
+	if len(t.appends) > 0 {
+		elems := t.appends
+		t.appends = nil
+		t.tree.root = join(t.tree.root, elems[0], build(elems[1:], t.tree.aug), t.tree.aug)
+	}
+	if len(t.buffered) > 0 {
+		elems := sortUnique(t.buffered)
+		t.buffered = nil
+		var m int
+		t.tree.root, m = t.tree.root.union(elems, nil, t.tree.aug)
+		t.tree.size += m
+	}
+}
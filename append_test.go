@@ -0,0 +1,60 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "testing"
+
+func TestAppend(t *testing.T) {
+	tree := New(WithStats())
+	txn := tree.Txn()
+	for i := compInt(0); i < 1000; i++ {
+		txn.Append(i)
+		if i%97 == 0 && txn.Get(i) != i {
+			t.Fatalf("append: expected %v in transaction", i)
+		}
+	}
+	txn.Append(compInt(500)) // not ascending, replaces via Insert
+	tree = txn.Commit()
+	checkTree(t, "append", tree)
+	if tree.Len() != 1000 {
+		t.Fatalf("append: expected 1000 elements, have %d", tree.Len())
+	}
+	for i, elem := range elements(tree) {
+		if elem != compInt(i) {
+			t.Fatalf("append: expected element %d, have %v", i, elem)
+		}
+	}
+	if s := tree.Stats().Insert; s.Count != 1001 || s.Compares > 1100 {
+		t.Fatalf("append: expected 1001 inserts with few compares, have %d with %d", s.Count, s.Compares)
+	}
+
+	prev := tree
+	txn = tree.Txn()
+	txn.Append(compInt(1000))
+	tree = txn.Commit()
+	if prev.Len() != 1000 || tree.Len() != 1001 || !prev.isBalanced() {
+		t.Fatalf("append: previous version modified")
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		txn := (&Tree{}).Txn()
+		for j := compInt(0); j < 10000; j++ {
+			txn.Append(j)
+		}
+		txn.Commit()
+	}
+}
+
+func BenchmarkAppendInsert(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		txn := (&Tree{}).Txn()
+		for j := compInt(0); j < 10000; j++ {
+			txn.Insert(j)
+		}
+		txn.Commit()
+	}
+}
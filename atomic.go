@@ -0,0 +1,34 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "sync/atomic"
+
+// Atomic holds the current version of a tree. Any number of goroutines
+// may Load the current version without locking while writers publish
+// new versions. The zero Atomic holds a nil *Tree, which behaves like
+// an empty tree.
+type Atomic struct {
+	p atomic.Pointer[Tree]
+}
+
+// NewAtomic returns an Atomic holding t.
+func NewAtomic(t *Tree) *Atomic {
+	a := &Atomic{}
+	a.p.Store(t)
+	return a
+}
+
+// Load returns the current version of the tree.
+func (a *Atomic) Load() *Tree { return a.p.Load() }
+
+// Store publishes t as the current version of the tree.
+func (a *Atomic) Store(t *Tree) { a.p.Store(t) }
+
+// CompareAndSwap publishes new as the current version of the tree if
+// the current version is old, and reports whether it did.
+func (a *Atomic) CompareAndSwap(old, new *Tree) bool {
+	return a.p.CompareAndSwap(old, new)
+}
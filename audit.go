@@ -0,0 +1,83 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "fmt"
+
+// WithMutationAudit makes Range and ForEach check that the elements they
+// visit are in ascending order and panic with a *MutationError if they
+// are not, which happens when stored elements are mutated in a way that
+// changes their sort order. The check costs a Compare call per element
+// visited, so the option is meant for debugging.
+func WithMutationAudit() Option {
+	return func(t *Tree) {
+		t.audit = true
+	}
+}
+
+// A MutationError reports two adjacent elements of a tree found out of
+// order, one of which has been mutated in place since it was inserted.
+type MutationError struct {
+	Prev, Elem Element
+}
+
+func (e *MutationError) Error() string {
+	return fmt.Sprintf("llrb: element %v stored before %v is not less, an element was mutated in place", e.Prev, e.Elem)
+}
+
+// auditVisitor returns a Visitor calling fn that panics with a
+// MutationError if it is not called with ascending elements.
+func auditVisitor(fn Visitor) Visitor {
+	var prev Element
+	return func(elem Element) bool {
+		if prev != nil && prev.Compare(elem) >= 0 {
+			panic(&MutationError{Prev: prev, Elem: elem})
+		}
+		prev = elem
+		return fn(elem)
+	}
+}
+
+// Mutated returns the elements of the tree that appear to have been
+// mutated in place: both elements of every adjacent pair that is out of
+// order and, if the tree keeps digests, every Digester whose digest
+// differs from the one recorded in its node when it was inserted. The
+// elements are returned in tree order without duplicates. Mutated
+// visits every element, so its cost is linear in the size of the tree.
+func (t *Tree) Mutated() []Element {
+	if t == nil {
+		return nil
+	}
+	var (
+		bad        []Element
+		prev, last *node // last is the node added last
+	)
+	add := func(n *node) {
+		if n != last {
+			bad, last = append(bad, n.elem), n
+		}
+	}
+	t.root.doNodes(func(n *node) {
+		if prev != nil && prev.elem.Compare(n.elem) >= 0 {
+			add(prev)
+			add(n)
+		}
+		if n.kinds()&augDigest != 0 && n.aug.digest != digestOf(n.elem) {
+			add(n)
+		}
+		prev = n
+	})
+	return bad
+}
+
+// doNodes performs fn on the nodes of the subtree rooted at n in order.
+func (n *node) doNodes(fn func(*node)) {
+	if n == nil {
+		return
+	}
+	n.left.doNodes(fn)
+	fn(n)
+	n.right.doNodes(fn)
+}
@@ -0,0 +1,72 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+// mutable is an element that can be mutated in place.
+type mutable struct{ v *int }
+
+func (m mutable) Compare(elem Element) int { return *m.v - *elem.(mutable).v }
+
+// mutableDigest is a mutable Digester.
+type mutableDigest struct{ mutable }
+
+func (m mutableDigest) Compare(elem Element) int {
+	return *m.v - *elem.(mutableDigest).v
+}
+
+func (m mutableDigest) Digest() uint64 { return uint64(*m.v) }
+
+func TestMutationAudit(t *testing.T) {
+	vals := make([]int, 10)
+	tree := New(WithMutationAudit())
+	txn := tree.Txn()
+	for i := range vals {
+		vals[i] = 10 * i
+		txn.Insert(mutable{&vals[i]})
+	}
+	tree = txn.Commit()
+	if bad := tree.Mutated(); len(bad) != 0 {
+		t.Fatalf("mutated: unexpected offenders %v", bad)
+	}
+	tree.ForEach(func(Element) bool { return false })
+
+	vals[3] = 35 // still between its neighbors
+	if bad := tree.Mutated(); len(bad) != 0 {
+		t.Fatalf("mutated: unexpected offenders %v", bad)
+	}
+	vals[5] = 75
+	if bad, want := tree.Mutated(), []Element{mutable{&vals[5]}, mutable{&vals[6]}}; !reflect.DeepEqual(bad, want) {
+		t.Fatalf("mutated: expected %v, have %v", want, bad)
+	}
+	func() {
+		defer func() {
+			err, ok := recover().(*MutationError)
+			if !ok || *err.Prev.(mutable).v != 75 || *err.Elem.(mutable).v != 60 {
+				t.Fatalf("audit: expected mutation error, got %v", err)
+			}
+		}()
+		tree.Range(mutable{&vals[0]}, mutable{&vals[9]}, func(Element) bool { return false })
+	}()
+
+	dvals := []int{1, 2, 3}
+	txn = New(WithDigests()).Txn()
+	for i := range dvals {
+		txn.Insert(mutableDigest{mutable{&dvals[i]}})
+	}
+	tree = txn.Commit()
+	dvals[1] = 2 // unchanged
+	if bad := tree.Mutated(); len(bad) != 0 {
+		t.Fatalf("mutated: unexpected offenders %v", bad)
+	}
+	dvals[0] = 0
+	if bad := tree.Mutated(); len(bad) != 1 || *bad[0].(mutableDigest).v != 0 {
+		t.Fatalf("mutated: expected digest offender, have %v", bad)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// augKinds is the set of augmentations a tree keeps in its nodes in
+// addition to the subtree size. Each is enabled by an Option, and the
+// nodes of a tree without any carry no augmentation data at all.
+type augKinds uint8
+
+const (
+	augGap    augKinds = 1 << iota // positions and gaps, see WithGaps
+	augDigest                      // element digests, see WithDigests
+	augWeight                      // subtree weights, see WithWeights
+	augBytes                       // subtree byte sizes, see WithSizes
+)
+
+// augment holds the augmentations of a node.
+type augment struct {
+	kinds augKinds
+
+	digest uint64  // digest of elem
+	weight float64 // sum of the weights of the elements in the subtree
+	bytes  int     // sum of the sizes of the elements in the subtree
+
+	lo, hi         int64 // smallest and largest position in the subtree
+	minGap, maxGap int64 // distances between adjacent positions in the subtree
+}
+
+// augNode is a node allocated together with its augment, so that
+// augmented trees need no second allocation per node.
+type augNode struct {
+	node
+	augment
+}
+
+// alloc returns a new empty node keeping the augmentations k.
+func alloc(k augKinds) *node {
+	if k == 0 {
+		return &node{}
+	}
+	a := &augNode{augment: augment{kinds: k}}
+	a.aug = &a.augment
+	return &a.node
+}
+
+// kinds returns the augmentations kept in the subtree rooted at n.
+func (n *node) kinds() augKinds {
+	if n == nil || n.aug == nil {
+		return 0
+	}
+	return n.aug.kinds
+}
+
+// updateAug recomputes the augmentations of n, which must have some,
+// after its element or children changed.
+func (n *node) updateAug() {
+	if n.aug.kinds&augDigest != 0 {
+		n.aug.digest = digestOf(n.elem)
+	}
+	if n.aug.kinds&augWeight != 0 {
+		n.aug.weight = weightOf(n.elem) + n.left.totalWeight() + n.right.totalWeight()
+	}
+	if n.aug.kinds&augBytes != 0 {
+		n.aug.bytes = sizeOf(n.elem) + n.left.totalBytes() + n.right.totalBytes()
+	}
+	if n.aug.kinds&augGap != 0 {
+		n.updateGap()
+	}
+}
+
+// kinds returns the augmentations kept in the nodes of t.
+func (t *Tree) kinds() augKinds {
+	if t == nil {
+		return 0
+	}
+	return t.aug
+}
@@ -0,0 +1,59 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrNoCodec is returned by MarshalBinary and UnmarshalBinary if the
+// tree has no Codec set by WithCodec.
+var ErrNoCodec = errors.New("llrb: no codec")
+
+// WithCodec sets the Codec encoding elements for MarshalBinary and
+// UnmarshalBinary, which implement encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler on trees.
+func WithCodec(c Codec) Option {
+	return func(t *Tree) {
+		t.codec = c
+	}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface,
+// encoding the tree in the snapshot format written by Persist with the
+// Codec set by WithCodec.
+func (t *Tree) MarshalBinary() ([]byte, error) {
+	if t == nil || t.codec == nil {
+		return nil, ErrNoCodec
+	}
+	var buf bytes.Buffer
+	if err := t.Persist(&buf, t.codec); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface,
+// replacing the elements of the tree by those decoded from data with the
+// Codec set by WithCodec; the options of the tree are kept. Trees are
+// otherwise immutable, so UnmarshalBinary must only be called on a tree
+// not yet shared, typically one just returned by New.
+func (t *Tree) UnmarshalBinary(data []byte) error {
+	if t.codec == nil {
+		return ErrNoCodec
+	}
+	r, err := Restore(bytes.NewReader(data), t.codec)
+	if err != nil {
+		return err
+	}
+	t.root, t.size = r.root, r.size
+	t.deletes, t.meta = 0, nil
+	t.bloom = nil
+	if t.bloomBits > 0 {
+		t.bloom = buildBloom(t)
+	}
+	return nil
+}
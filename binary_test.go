@@ -0,0 +1,50 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Tree)(nil)
+	_ encoding.BinaryUnmarshaler = (*Tree)(nil)
+)
+
+func TestMarshalBinary(t *testing.T) {
+	txn := New(WithCodec(intCodec{})).Txn()
+	for i := compInt(0); i < 1000; i += 3 {
+		txn.Insert(i)
+	}
+	tree := txn.Commit()
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary: %v", err)
+	}
+
+	restored := New(WithCodec(intCodec{}))
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal binary: %v", err)
+	}
+	if err := restored.Check(); err != nil {
+		t.Fatalf("unmarshal binary: %v", err)
+	}
+	if !reflect.DeepEqual(elements(restored), elements(tree)) {
+		t.Fatalf("unmarshal binary: restored tree differs")
+	}
+	if err := restored.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrSnapshot) {
+		t.Fatalf("unmarshal binary: expected ErrSnapshot, have %v", err)
+	}
+
+	if _, err := New().MarshalBinary(); err != ErrNoCodec {
+		t.Fatalf("marshal binary: expected ErrNoCodec, have %v", err)
+	}
+	if err := New().UnmarshalBinary(data); err != ErrNoCodec {
+		t.Fatalf("unmarshal binary: expected ErrNoCodec, have %v", err)
+	}
+}
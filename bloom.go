@@ -0,0 +1,167 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "math"
+
+// Hasher is implemented by elements that can be stored in the bloom
+// filter enabled by WithBloom. Elements that compare equal must return
+// the same hash.
+type Hasher interface {
+	Hash() uint64
+}
+
+// WithBloom maintains a bloom filter with roughly bitsPerElem bits per
+// stored element alongside the tree, so Get can return nil for most
+// absent elements without descending the tree. Ten bits per element
+// give a false positive rate of about one percent.
+//
+// The filter is brought up to date when a transaction is committed.
+// All stored elements and queries must implement Hasher; while the tree
+// holds an element that does not, the filter is disabled and rebuilt on
+// every commit.
+func WithBloom(bitsPerElem int) Option {
+	return func(t *Tree) {
+		if bitsPerElem > 0 {
+			t.bloomBits = bitsPerElem
+		}
+	}
+}
+
+type bloom struct {
+	bits    []uint64
+	k       int
+	cap     int // number of elements the filter was sized for
+	deleted int // elements deleted since the filter was built
+}
+
+func newBloom(n, bitsPerElem int) *bloom {
+	if n < 64 {
+		n = 64
+	}
+	k := int(float64(bitsPerElem)*math.Ln2 + 0.5)
+	if k < 1 {
+		k = 1
+	}
+	return &bloom{bits: make([]uint64, (n*bitsPerElem+63)/64), k: k, cap: n}
+}
+
+// buildBloom returns a filter holding all elements of t, or nil if t
+// stores an element that is not a Hasher.
+func buildBloom(t *Tree) *bloom {
+	b := newBloom(2*t.size, t.bloomBits)
+	if t.root == nil {
+		return b
+	}
+	if t.root.do(func(elem Element) bool {
+		h, ok := elem.(Hasher)
+		if ok {
+			b.add(h.Hash())
+		}
+		return !ok
+	}) {
+		return nil
+	}
+	return b
+}
+
+func (b *bloom) clone() *bloom {
+	c := *b
+	c.bits = append([]uint64(nil), b.bits...)
+	return &c
+}
+
+// locations derives the k bit positions of h by double hashing.
+func (b *bloom) locations(h uint64, fn func(uint64) bool) bool {
+	m := uint64(len(b.bits)) * 64
+	h2 := h>>32 | h<<32 | 1
+	for i := 0; i < b.k; i++ {
+		if !fn((h + uint64(i)*h2) % m) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bloom) add(h uint64) {
+	b.locations(h, func(i uint64) bool {
+		b.bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+}
+
+// mayContain reports whether elem may be stored in the tree. It
+// returns true if the filter is disabled or elem is not a Hasher.
+func (b *bloom) mayContain(elem Element) bool {
+	if b == nil {
+		return true
+	}
+	h, ok := elem.(Hasher)
+	if !ok {
+		return true
+	}
+	return b.locations(h.Hash(), func(i uint64) bool {
+		return b.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+// bloomTxn records the changes of a transaction that affect the bloom
+// filter. The tree of a running transaction carries no filter, as it
+// would not know about elements inserted since the transaction began.
+type bloomTxn struct {
+	enabled bool
+	base    *bloom
+	added   []uint64
+	deleted int
+	stale   bool // an element without a hash was inserted
+}
+
+func (b *bloomTxn) begin(t *Tree) {
+	if t.bloomBits == 0 {
+		return
+	}
+	b.enabled = true
+	b.base, t.bloom = t.bloom, nil
+}
+
+func (b *bloomTxn) insert(elem Element) {
+	if !b.enabled {
+		return
+	}
+	if h, ok := elem.(Hasher); ok {
+		b.added = append(b.added, h.Hash())
+	} else {
+		b.stale = true
+	}
+}
+
+func (b *bloomTxn) delete(m int) {
+	if b.enabled {
+		b.deleted -= m
+	}
+}
+
+// commit installs an up-to-date filter on t. The filter is rebuilt from
+// scratch when it has grown past its capacity or too many of its
+// elements have been deleted, and extended by the inserted elements
+// otherwise.
+func (b *bloomTxn) commit(t *Tree) {
+	if !b.enabled {
+		return
+	}
+	switch {
+	case b.base == nil, b.stale, t.size > b.base.cap, b.base.deleted+b.deleted > b.base.cap/2:
+		t.bloom = buildBloom(t)
+	case len(b.added) == 0 && b.deleted == 0:
+		t.bloom = b.base
+	default:
+		t.bloom = b.base.clone()
+		for _, h := range b.added {
+			t.bloom.add(h)
+		}
+		t.bloom.deleted += b.deleted
+	}
+	*b = bloomTxn{enabled: true, base: t.bloom}
+}
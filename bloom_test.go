@@ -0,0 +1,80 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "testing"
+
+// hashInt is a compInt that counts comparisons and implements Hasher.
+type hashInt int
+
+var hashCompares int
+
+func (i hashInt) Compare(elem Element) int {
+	hashCompares++
+	return int(i - elem.(hashInt))
+}
+
+func (i hashInt) Hash() uint64 { return uint64(i) * 0x9e3779b97f4a7c15 }
+
+func TestBloom(t *testing.T) {
+	tree := New(WithBloom(10))
+	for i := 0; i < 5; i++ {
+		txn := tree.Txn()
+		for j := 0; j < 1000; j++ {
+			txn.Insert(hashInt(2 * (i*1000 + j)))
+		}
+		if txn.Get(hashInt(2*i*1000)) == nil {
+			t.Fatalf("bloom: element missing from open transaction")
+		}
+		tree = txn.Commit()
+	}
+	if tree.bloom == nil {
+		t.Fatalf("bloom: expected filter to be maintained")
+	}
+
+	for i := 0; i < 5000; i++ {
+		if tree.Get(hashInt(2*i)) != hashInt(2*i) {
+			t.Fatalf("bloom: expected element %d, got %v", 2*i, tree.Get(hashInt(2*i)))
+		}
+	}
+
+	hashCompares = 0
+	for i := 0; i < 5000; i++ {
+		if tree.Get(hashInt(2*i+1)) != nil {
+			t.Fatalf("bloom: unexpected element found %v", tree.Get(hashInt(2*i+1)))
+		}
+	}
+	if hashCompares > 5000 {
+		t.Fatalf("bloom: expected most misses to skip the tree, have %d comparisons", hashCompares)
+	}
+
+	txn := tree.Txn()
+	for i := 0; i < 5000; i += 2 {
+		txn.Delete(hashInt(2 * i))
+	}
+	tree = txn.Commit()
+	for i := 0; i < 5000; i++ {
+		want := Element(hashInt(2 * i))
+		if i%2 == 0 {
+			want = nil
+		}
+		if tree.Get(hashInt(2*i)) != want {
+			t.Fatalf("bloom: expected element %v, got %v", want, tree.Get(hashInt(2*i)))
+		}
+	}
+}
+
+func TestBloomWithoutHasher(t *testing.T) {
+	tree := New(WithBloom(10))
+	txn := tree.Txn()
+	txn.Insert(compInt(1))
+	tree = txn.Commit()
+	if tree.bloom != nil {
+		t.Fatalf("bloom: expected filter to be disabled")
+	}
+	if tree.Get(compInt(1)) != compInt(1) {
+		t.Fatalf("bloom: expected element %v, got %v", compInt(1), tree.Get(compInt(1)))
+	}
+}
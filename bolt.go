@@ -0,0 +1,75 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// BoltBucket is the part of a bbolt bucket (*bbolt.Bucket of
+// go.etcd.io/bbolt) used to persist trees in it. Any key-value store
+// with the same methods can be used.
+type BoltBucket interface {
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	ForEach(fn func(k, v []byte) error) error
+}
+
+// SaveBucket writes all elements of t to b, each under the key returned
+// by key and encoded with c. key must identify elements as Compare
+// does. Keys of b not belonging to elements of t are left alone, so a
+// tree is usually saved once to an empty bucket and kept up to date with
+// SaveChanges.
+func SaveBucket(b BoltBucket, t *Tree, key func(Element) []byte, c Codec) error {
+	var err error
+	t.ForEach(func(elem Element) bool {
+		err = putElement(b, elem, key, c)
+		return err != nil
+	})
+	return err
+}
+
+// SaveChanges applies the changes of a transaction tracking changes,
+// as returned by Changes before Commit, to b, writing inserted elements
+// and deleting the keys of deleted ones, so each commit costs writes for
+// the changed elements only. Run it in the same bbolt transaction for
+// all changes of a commit to keep b consistent with the committed tree.
+func SaveChanges(b BoltBucket, changes []Change, key func(Element) []byte, c Codec) error {
+	for _, ch := range changes {
+		var err error
+		if ch.Op == ChangeInsert {
+			err = putElement(b, ch.Elem, key, c)
+		} else {
+			err = b.Delete(key(ch.Elem))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putElement(b BoltBucket, elem Element, key func(Element) []byte, c Codec) error {
+	v, err := c.Marshal(elem)
+	if err != nil {
+		return err
+	}
+	return b.Put(key(elem), v)
+}
+
+// LoadBucket returns a balanced tree configured by opts holding the
+// elements stored in b, decoded with c. The tree is built in a single
+// pass, as by LoadRows.
+func LoadBucket(b BoltBucket, c Codec, opts ...Option) (*Tree, error) {
+	var elems []Element
+	err := b.ForEach(func(_, v []byte) error {
+		elem, err := c.Unmarshal(v)
+		if err != nil {
+			return err
+		}
+		elems = append(elems, elem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromElements(elems, opts...), nil
+}
@@ -0,0 +1,71 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// mapBucket is an in-memory BoltBucket iterating in key order, like bbolt.
+type mapBucket map[string][]byte
+
+func (b mapBucket) Put(k, v []byte) error { b[string(k)] = append([]byte(nil), v...); return nil }
+func (b mapBucket) Delete(k []byte) error { delete(b, string(k)); return nil }
+
+func (b mapBucket) ForEach(fn func(k, v []byte) error) error {
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn([]byte(k), b[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func intKey(elem Element) []byte { return strconv.AppendInt(nil, int64(elem.(compInt)), 10) }
+
+func TestBucket(t *testing.T) {
+	b := mapBucket{}
+	tree := randomTree(500, 2000)
+	if err := SaveBucket(b, tree, intKey, intCodec{}); err != nil {
+		t.Fatalf("save bucket: %v", err)
+	}
+	if len(b) != tree.Len() {
+		t.Fatalf("save bucket: expected %d keys, have %d", tree.Len(), len(b))
+	}
+
+	txn := tree.Txn()
+	txn.TrackChanges()
+	for i := compInt(0); i < 100; i++ {
+		txn.Delete(i)
+		txn.Insert(2000 + i)
+	}
+	changes := txn.Changes()
+	tree = txn.Commit()
+	if err := SaveChanges(b, changes, intKey, intCodec{}); err != nil {
+		t.Fatalf("save changes: %v", err)
+	}
+
+	loaded, err := LoadBucket(b, intCodec{})
+	if err != nil {
+		t.Fatalf("load bucket: %v", err)
+	}
+	checkTree(t, "load bucket", loaded)
+	if !reflect.DeepEqual(elements(tree), elements(loaded)) {
+		t.Fatalf("load bucket: expected %v, have %v", elements(tree), elements(loaded))
+	}
+
+	b["x"] = []byte("not a number")
+	if _, err := LoadBucket(b, intCodec{}); err == nil {
+		t.Fatalf("load bucket: expected decode error")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package btree provides the API of github.com/google/btree on top of
+// an immutable llrb tree, so code written against that package can
+// switch by changing the import and constructor. Clone takes constant
+// time, as every version of the tree is immutable.
+package btree
+
+import "github.com/mars9/llrb"
+
+// Item represents a single object in the tree.
+type Item interface {
+	// Less tests whether the current item is less than the given
+	// argument. Two items a and b are treated as equal if
+	// !a.Less(b) && !b.Less(a).
+	Less(than Item) bool
+}
+
+// ItemIterator allows callers of Ascend* and Descend* to iterate
+// in-order over portions of the tree. When this function returns false,
+// iteration will stop.
+type ItemIterator func(i Item) bool
+
+// item stores an Item in the llrb tree.
+type item struct{ Item }
+
+// Compare implements the llrb.Element interface.
+func (i item) Compare(elem llrb.Element) int {
+	switch e := elem.(type) {
+	case item:
+		switch {
+		case i.Less(e.Item):
+			return -1
+		case e.Less(i.Item):
+			return 1
+		}
+		return 0
+	case after:
+		return -e.Compare(i)
+	}
+	panic("btree: unknown element type")
+}
+
+// after is a bound placed right after the items equal to pivot, so that
+// the exclusive bounds of llrb queries can express inclusive ones.
+type after struct{ pivot Item }
+
+// Compare implements the llrb.Element interface. An after bound never
+// compares equal to an item.
+func (a after) Compare(elem llrb.Element) int {
+	switch e := elem.(type) {
+	case item:
+		if a.pivot.Less(e.Item) {
+			return -1
+		}
+		return 1
+	case after:
+		return item{a.pivot}.Compare(item{e.pivot})
+	}
+	panic("btree: unknown element type")
+}
+
+// BTree is an ordered collection of items. Like its namesake it is not
+// safe for concurrent modification, but clones are independent of each
+// other and may be used by different goroutines.
+type BTree struct {
+	tree *llrb.Tree
+}
+
+// New creates a new tree. The degree is accepted for compatibility and
+// ignored.
+func New(degree int) *BTree {
+	return &BTree{tree: llrb.New()}
+}
+
+// Clone returns a copy of the tree in constant time. Modifications of
+// either tree are not seen by the other.
+func (t *BTree) Clone() *BTree {
+	return &BTree{tree: t.tree}
+}
+
+// Tree returns the current version of the underlying llrb tree, whose
+// elements wrap the items.
+func (t *BTree) Tree() *llrb.Tree { return t.tree }
+
+// ReplaceOrInsert adds the given item to the tree. If an item in the
+// tree already equals the given one, it is removed from the tree and
+// returned. Otherwise, nil is returned. nil cannot be added to the tree.
+func (t *BTree) ReplaceOrInsert(i Item) Item {
+	if i == nil {
+		panic("btree: nil item being added to BTree")
+	}
+	old := t.Get(i)
+	txn := t.tree.Txn()
+	txn.Insert(item{i})
+	t.tree = txn.Commit()
+	return old
+}
+
+// Delete removes an item equal to the passed in item from the tree,
+// returning it. If no such item exists, returns nil.
+func (t *BTree) Delete(i Item) Item {
+	old := t.Get(i)
+	if old != nil {
+		txn := t.tree.Txn()
+		txn.Delete(item{i})
+		t.tree = txn.Commit()
+	}
+	return old
+}
+
+// DeleteMin removes the smallest item in the tree and returns it. If no
+// such item exists, returns nil.
+func (t *BTree) DeleteMin() Item {
+	return t.Delete(t.Min())
+}
+
+// DeleteMax removes the largest item in the tree and returns it. If no
+// such item exists, returns nil.
+func (t *BTree) DeleteMax() Item {
+	return t.Delete(t.Max())
+}
+
+// Clear removes all items from the tree. The argument is accepted for
+// compatibility and ignored; nodes are shared with clones and reclaimed
+// by the garbage collector.
+func (t *BTree) Clear(addNodesToFreelist bool) {
+	t.tree = llrb.New()
+}
+
+// Get looks for the key item in the tree, returning it. It returns nil
+// if unable to find that item.
+func (t *BTree) Get(key Item) Item {
+	if key == nil {
+		return nil
+	}
+	return unwrap(t.tree.Get(item{key}))
+}
+
+// Has returns true if the given key is in the tree.
+func (t *BTree) Has(key Item) bool {
+	return t.Get(key) != nil
+}
+
+// Min returns the smallest item in the tree, or nil if the tree is
+// empty.
+func (t *BTree) Min() Item { return unwrap(t.tree.Min()) }
+
+// Max returns the largest item in the tree, or nil if the tree is
+// empty.
+func (t *BTree) Max() Item { return unwrap(t.tree.Max()) }
+
+// Len returns the number of items currently in the tree.
+func (t *BTree) Len() int { return t.tree.Len() }
+
+// Ascend calls the iterator for every value in the tree, in ascending
+// order, until iterator returns false.
+func (t *BTree) Ascend(iterator ItemIterator) {
+	t.each(t.tree.Query(), iterator)
+}
+
+// AscendRange calls the iterator for every value in the tree within the
+// range [greaterOrEqual, lessThan), until iterator returns false.
+func (t *BTree) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	if lessThan.Less(greaterOrEqual) {
+		return
+	}
+	t.each(t.tree.Query().From(item{greaterOrEqual}).To(item{lessThan}), iterator)
+}
+
+// AscendLessThan calls the iterator for every value in the tree within
+// the range [first, pivot), until iterator returns false.
+func (t *BTree) AscendLessThan(pivot Item, iterator ItemIterator) {
+	t.each(t.tree.Query().To(item{pivot}), iterator)
+}
+
+// AscendGreaterOrEqual calls the iterator for every value in the tree
+// within the range [pivot, last], until iterator returns false.
+func (t *BTree) AscendGreaterOrEqual(pivot Item, iterator ItemIterator) {
+	t.each(t.tree.Query().From(item{pivot}), iterator)
+}
+
+// Descend calls the iterator for every value in the tree, in descending
+// order, until iterator returns false.
+func (t *BTree) Descend(iterator ItemIterator) {
+	t.each(t.tree.Query().Reverse(), iterator)
+}
+
+// DescendRange calls the iterator for every value in the tree within
+// the range [lessOrEqual, greaterThan), in descending order, until
+// iterator returns false.
+func (t *BTree) DescendRange(lessOrEqual, greaterThan Item, iterator ItemIterator) {
+	if lessOrEqual.Less(greaterThan) {
+		return
+	}
+	t.each(t.tree.Query().From(after{greaterThan}).To(after{lessOrEqual}).Reverse(), iterator)
+}
+
+// DescendLessOrEqual calls the iterator for every value in the tree
+// within the range [pivot, first], in descending order, until iterator
+// returns false.
+func (t *BTree) DescendLessOrEqual(pivot Item, iterator ItemIterator) {
+	t.each(t.tree.Query().To(after{pivot}).Reverse(), iterator)
+}
+
+// DescendGreaterThan calls the iterator for every value in the tree
+// within the range [last, pivot), in descending order, until iterator
+// returns false.
+func (t *BTree) DescendGreaterThan(pivot Item, iterator ItemIterator) {
+	t.each(t.tree.Query().From(after{pivot}).Reverse(), iterator)
+}
+
+// each performs iterator on the items selected by q.
+func (t *BTree) each(q llrb.Query, iterator ItemIterator) {
+	q.Each(func(elem llrb.Element) bool {
+		return !iterator(elem.(item).Item)
+	})
+}
+
+// unwrap returns the item stored in elem, or nil.
+func unwrap(elem llrb.Element) Item {
+	if i, ok := elem.(item); ok {
+		return i.Item
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Int implements the Item interface for integers.
+type Int int
+
+func (a Int) Less(b Item) bool { return a < b.(Int) }
+
+// collect returns an iterator appending items to s, stopping after
+// limit items if limit is positive.
+func collect(s *[]Item, limit int) ItemIterator {
+	return func(i Item) bool {
+		*s = append(*s, i)
+		return limit <= 0 || len(*s) < limit
+	}
+}
+
+func ints(v ...int) []Item {
+	items := make([]Item, len(v))
+	for i, x := range v {
+		items[i] = Int(x)
+	}
+	return items
+}
+
+func TestBTree(t *testing.T) {
+	tr := New(32)
+	for _, v := range []int{5, 1, 9, 3, 7} {
+		if old := tr.ReplaceOrInsert(Int(v)); old != nil {
+			t.Fatalf("replace or insert: unexpected old item %v", old)
+		}
+	}
+	if old := tr.ReplaceOrInsert(Int(3)); old != Int(3) {
+		t.Fatalf("replace or insert: expected old item 3, have %v", old)
+	}
+	clone := tr.Clone()
+	if tr.Delete(Int(4)) != nil || tr.Delete(Int(5)) != Int(5) || tr.Has(Int(5)) {
+		t.Fatalf("delete: unexpected result")
+	}
+	if tr.Len() != 4 || clone.Len() != 5 || !clone.Has(Int(5)) {
+		t.Fatalf("clone: expected independent trees, have lengths %d and %d", tr.Len(), clone.Len())
+	}
+	if tr.Min() != Int(1) || tr.Max() != Int(9) {
+		t.Fatalf("min max: expected 1 and 9, have %v and %v", tr.Min(), tr.Max())
+	}
+
+	for _, test := range []struct {
+		name string
+		run  func(ItemIterator)
+		want []Item
+	}{
+		{"ascend", func(it ItemIterator) { clone.Ascend(it) }, ints(1, 3, 5, 7, 9)},
+		{"ascend range", func(it ItemIterator) { clone.AscendRange(Int(3), Int(7), it) }, ints(3, 5)},
+		{"ascend less than", func(it ItemIterator) { clone.AscendLessThan(Int(5), it) }, ints(1, 3)},
+		{"ascend greater or equal", func(it ItemIterator) { clone.AscendGreaterOrEqual(Int(5), it) }, ints(5, 7, 9)},
+		{"descend", func(it ItemIterator) { clone.Descend(it) }, ints(9, 7, 5, 3, 1)},
+		{"descend range", func(it ItemIterator) { clone.DescendRange(Int(7), Int(3), it) }, ints(7, 5)},
+		{"descend less or equal", func(it ItemIterator) { clone.DescendLessOrEqual(Int(5), it) }, ints(5, 3, 1)},
+		{"descend greater than", func(it ItemIterator) { clone.DescendGreaterThan(Int(5), it) }, ints(9, 7)},
+		{"empty range", func(it ItemIterator) { clone.DescendRange(Int(3), Int(7), it) }, nil},
+	} {
+		var have []Item
+		test.run(collect(&have, 0))
+		if !reflect.DeepEqual(have, test.want) {
+			t.Fatalf("%s: expected %v, have %v", test.name, test.want, have)
+		}
+	}
+
+	var have []Item
+	clone.Ascend(collect(&have, 2))
+	if !reflect.DeepEqual(have, ints(1, 3)) {
+		t.Fatalf("ascend: expected iteration to stop after 2 items, have %v", have)
+	}
+
+	if tr.DeleteMin() != Int(1) || tr.DeleteMax() != Int(9) || tr.Len() != 2 {
+		t.Fatalf("delete min max: unexpected result")
+	}
+	tr.Clear(true)
+	if tr.Len() != 0 || tr.DeleteMin() != nil || clone.Len() != 5 {
+		t.Fatalf("clear: unexpected result")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "sort"
+
+// BufferInsert stages elem for insertion. Staged elements are sorted
+// and merged into the tree in one pass by the next operation on the
+// transaction other than BufferInsert and Append, typically Commit.
+// The merge splits the batch along the tree and joins the results, so
+// subtrees that receive no new elements are shared rather than copied,
+// which makes large batch loads much cheaper than inserting elements
+// one at a time. Of staged elements that compare equal the last one is
+// inserted, as with Insert.
+func (t *Txn) BufferInsert(elem Element) {
+	t.own()
+	defer t.leave()
+
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	if len(t.appends) > 0 {
+		t.flush()
+	}
+	var p *probe
+	if t.probe() != nil {
+		p = &probe{}
+	}
+	t.account(opInsert, p)
+	t.buffered = append(t.buffered, elem)
+	t.bloom.insert(elem)
+	t.record(ChangeInsert, elem)
+}
+
+// union returns a tree holding the elements of n and the sorted, unique
+// elems, which replace the elements of n they compare equal to, and the
+// number of elements added. If resolve is not nil, an element of n and
+// an element of elems comparing equal are replaced by resolve(old, new)
+// instead. n is not modified, and new nodes keep the augmentations k.
+func (n *node) union(elems []Element, resolve Resolver, k augKinds) (*node, int) {
+	if len(elems) == 0 {
+		return n, 0
+	}
+	if n == nil {
+		return build(elems, k), len(elems)
+	}
+	i := sort.Search(len(elems), func(i int) bool { return elems[i].Compare(n.elem) >= 0 })
+	elem, j := n.elem, i
+	if i < len(elems) && elems[i].Compare(n.elem) == 0 {
+		elem, j = resolve.apply(n.elem, elems[i]), i+1
+	}
+	l, ml := n.left.union(elems[:i], resolve, k)
+	r, mr := n.right.union(elems[j:], resolve, k)
+	return join(l, elem, r, k), ml + mr
+}
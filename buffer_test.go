@@ -0,0 +1,60 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBufferInsert(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 1000} {
+		for _, batch := range []int{1, 10, 1000, 5000} {
+			tree := randomTree(n, 4*n+1)
+			want := tree.Txn()
+			txn := tree.Txn()
+			for i := 0; i < batch; i++ {
+				elem := compInt(rand.Intn(4*n + batch))
+				want.Insert(elem)
+				txn.BufferInsert(elem)
+				if i == batch/2 {
+					txn.Append(compInt(1 << 30)) // buffered like BufferInsert
+					want.Insert(compInt(1 << 30))
+				}
+			}
+			merged := txn.Commit()
+			checkTree(t, "buffer insert", merged)
+			if w := want.Commit(); merged.Len() != w.Len() || !reflect.DeepEqual(elements(w), elements(merged)) {
+				t.Fatalf("buffer insert: expected %d elements, have %d", w.Len(), merged.Len())
+			}
+			if tree.Len() != n {
+				t.Fatalf("buffer insert: source tree modified")
+			}
+		}
+	}
+}
+
+func BenchmarkBufferInsert(b *testing.B) {
+	elems := rand.Perm(10000)
+	for i := 0; i < b.N; i++ {
+		txn := (&Tree{}).Txn()
+		for _, e := range elems {
+			txn.BufferInsert(compInt(e))
+		}
+		txn.Commit()
+	}
+}
+
+func BenchmarkBufferInsertInsert(b *testing.B) {
+	elems := rand.Perm(10000)
+	for i := 0; i < b.N; i++ {
+		txn := (&Tree{}).Txn()
+		for _, e := range elems {
+			txn.Insert(compInt(e))
+		}
+		txn.Commit()
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"container/heap"
+	"errors"
+	"io"
+	"os"
+)
+
+// Builder collects an unbounded stream of elements in any order and
+// turns it into a tree or a snapshot. At most runSize elements are held
+// in memory: whenever that many have been added they are sorted and
+// spilled to a temporary file in the snapshot format, and the sorted
+// runs are merged at the end. Of elements that compare equal the one
+// added last is kept, as with Insert. A Builder must be closed to
+// remove its temporary files.
+type Builder struct {
+	c       Codec
+	dir     string
+	runSize int
+
+	buf  []Element
+	runs []string // names of the spilled runs, oldest first
+}
+
+// NewBuilder returns a Builder encoding spilled elements with c into
+// temporary files in dir, or the default directory for temporary files
+// if dir is empty. A runSize of 0 or less selects 1<<16 elements.
+func NewBuilder(c Codec, dir string, runSize int) *Builder {
+	if runSize <= 0 {
+		runSize = 1 << 16
+	}
+	return &Builder{c: c, dir: dir, runSize: runSize}
+}
+
+// Add adds elem to the build, spilling a sorted run once runSize
+// elements are held in memory.
+func (b *Builder) Add(elem Element) error {
+	b.buf = append(b.buf, elem)
+	if len(b.buf) < b.runSize {
+		return nil
+	}
+	return b.spill()
+}
+
+// spill writes the elements held in memory to a new run file.
+func (b *Builder) spill() error {
+	run := sortUnique(b.buf)
+	f, err := os.CreateTemp(b.dir, "llrb-run-*")
+	if err != nil {
+		return err
+	}
+	b.runs = append(b.runs, f.Name())
+	err = writeSnapshot(f, b.c, len(run), sliceEach(run))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	b.buf = b.buf[:0]
+	return err
+}
+
+// Tree returns a balanced tree configured by opts holding the merged
+// elements. The result must fit in memory; use Persist otherwise.
+func (b *Builder) Tree(opts ...Option) (*Tree, error) {
+	var elems []Element
+	err := b.merge(func(elem Element) error {
+		elems = append(elems, elem)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromElements(elems, opts...), nil
+}
+
+// Persist writes the merged elements to w in the snapshot format read
+// by Restore, without holding them in memory. The runs are merged
+// twice, first to count the elements for the snapshot header.
+func (b *Builder) Persist(w io.Writer) error {
+	count := 0
+	if err := b.merge(func(Element) error { count++; return nil }); err != nil {
+		return err
+	}
+	var err error
+	werr := writeSnapshot(w, b.c, count, func(fn Visitor) bool {
+		err = b.merge(func(elem Element) error {
+			if fn(elem) {
+				return errStop
+			}
+			return nil
+		})
+		return err != nil
+	})
+	if err != nil && err != errStop {
+		return err
+	}
+	return werr
+}
+
+// Close removes the temporary files of the Builder.
+func (b *Builder) Close() error {
+	var err error
+	for _, name := range b.runs {
+		if rerr := os.Remove(name); err == nil {
+			err = rerr
+		}
+	}
+	b.runs, b.buf = nil, nil
+	return err
+}
+
+// errStop stops a merge whose consumer is done.
+var errStop = errors.New("stop")
+
+// merge performs fn on the union of the spilled runs and the elements
+// held in memory in ascending order, keeping the most recently added of
+// elements that compare equal.
+func (b *Builder) merge(fn func(Element) error) error {
+	b.buf = sortUnique(b.buf)
+	var h mergeHeap
+	for i, name := range b.runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r, err := newSnapshotReader(f, b.c)
+		if err != nil {
+			return err
+		}
+		if err := h.push(r.next, i); err != nil {
+			return err
+		}
+	}
+	mem := b.buf
+	memNext := func() (Element, error) {
+		if len(mem) == 0 {
+			return nil, io.EOF
+		}
+		elem := mem[0]
+		mem = mem[1:]
+		return elem, nil
+	}
+	if err := h.push(memNext, len(b.runs)); err != nil {
+		return err
+	}
+
+	return h.drain(fn)
+}
+
+// mergeItem is the current element of a sorted source being merged.
+type mergeItem struct {
+	elem Element
+	src  int // position of the source, later sources win ties
+	next func() (Element, error)
+}
+
+// mergeHeap orders the current elements of the sources being merged.
+type mergeHeap struct {
+	items []mergeItem
+}
+
+// push adds the next element of a source to the heap, unless the source
+// is exhausted.
+func (h *mergeHeap) push(next func() (Element, error), src int) error {
+	elem, err := next()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	heap.Push(h, mergeItem{elem: elem, src: src, next: next})
+	return nil
+}
+
+// drain performs fn on the elements of all sources in ascending order,
+// keeping the element of the latest source of those that compare equal.
+func (h *mergeHeap) drain(fn func(Element) error) error {
+	for h.Len() > 0 {
+		top := h.items[0]
+		for h.Len() > 0 && h.items[0].elem.Compare(top.elem) == 0 {
+			it := heap.Pop(h).(mergeItem)
+			if err := h.push(it.next, it.src); err != nil {
+				return err
+			}
+		}
+		if err := fn(top.elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *mergeHeap) Len() int      { return len(h.items) }
+func (h *mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap) Less(i, j int) bool {
+	if c := h.items[i].elem.Compare(h.items[j].elem); c != 0 {
+		return c < 0
+	}
+	return h.items[i].src > h.items[j].src
+}
+func (h *mergeHeap) Push(x interface{}) { h.items = append(h.items, x.(mergeItem)) }
+func (h *mergeHeap) Pop() interface{} {
+	it := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return it
+}
+
+// sliceEach returns a function performing a Visitor on elems in order.
+func sliceEach(elems []Element) func(Visitor) bool {
+	return func(fn Visitor) bool {
+		for _, elem := range elems {
+			if fn(elem) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []int{0, 1, 99, 1000} {
+		b := NewBuilder(intCodec{}, dir, 100)
+		want := (&Tree{}).Txn()
+		for i := 0; i < n; i++ {
+			elem := compInt(rand.Intn(n))
+			want.Insert(elem)
+			if err := b.Add(elem); err != nil {
+				t.Fatalf("builder: %v", err)
+			}
+		}
+		if runs := len(b.runs); runs != n/100 {
+			t.Fatalf("builder: expected %d runs for %d elements, have %d", n/100, n, runs)
+		}
+
+		tree, err := b.Tree(WithStats())
+		if err != nil {
+			t.Fatalf("builder: %v", err)
+		}
+		checkTree(t, "builder", tree)
+		w := want.Commit()
+		if tree.Len() != w.Len() || !reflect.DeepEqual(elements(w), elements(tree)) {
+			t.Fatalf("builder: expected %d elements, have %d", w.Len(), tree.Len())
+		}
+
+		var buf bytes.Buffer
+		if err := b.Persist(&buf); err != nil {
+			t.Fatalf("builder: %v", err)
+		}
+		restored, err := Restore(&buf, intCodec{})
+		if err != nil {
+			t.Fatalf("builder: %v", err)
+		}
+		if !reflect.DeepEqual(elements(w), elements(restored)) {
+			t.Fatalf("builder: persisted snapshot differs")
+		}
+
+		if err := b.Close(); err != nil {
+			t.Fatalf("builder: %v", err)
+		}
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("builder: %d temporary files left", len(files))
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cache implements a bounded ordered cache backed by immutable
+// llrb trees, which evicts the least recently used elements.
+package cache
+
+import (
+	"sync"
+
+	"github.com/mars9/llrb"
+)
+
+// Cache holds up to a fixed number of elements in sort order, evicting
+// the least recently used one when full. Lookups through Get count as
+// uses, traversals of a Snapshot do not. It is safe for concurrent use.
+type Cache struct {
+	max int
+
+	mu      sync.Mutex // serializes modifications and uses
+	recency Recency
+	elems   llrb.Atomic
+}
+
+// New returns a Cache holding up to max elements. A max less than 1 is
+// treated as 1.
+func New(max int) *Cache {
+	if max < 1 {
+		max = 1
+	}
+	return &Cache{max: max}
+}
+
+// Get returns the element matching key and marks it as most recently
+// used, or returns nil if there is no such element.
+func (c *Cache) Get(key llrb.Element) llrb.Element {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem := c.elems.Load().Get(key)
+	if elem != nil {
+		c.recency.Touch(elem)
+	}
+	return elem
+}
+
+// Put inserts elem, replacing an element that compares equal, and marks
+// it as most recently used. If the cache was full, the least recently
+// used element is evicted and returned; otherwise Put returns nil.
+func (c *Cache) Put(elem llrb.Element) (evicted llrb.Element) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	txn := c.elems.Load().Txn()
+	txn.Insert(elem)
+	c.recency.Touch(elem)
+	if txn.Len() > c.max {
+		evicted = c.recency.Oldest()
+		c.recency.Remove(evicted)
+		txn.Delete(evicted)
+	}
+	c.elems.Store(txn.Commit())
+	return evicted
+}
+
+// Delete removes the element matching key and reports whether there was
+// one.
+func (c *Cache) Delete(key llrb.Element) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.recency.Remove(key) {
+		return false
+	}
+	txn := c.elems.Load().Txn()
+	txn.Delete(key)
+	c.elems.Store(txn.Commit())
+	return true
+}
+
+// Len returns the number of elements held.
+func (c *Cache) Len() int { return c.elems.Load().Len() }
+
+// Snapshot returns the elements held as an immutable tree, unaffected by
+// later modifications, for ordered traversals that do not count as
+// uses.
+func (c *Cache) Snapshot() *llrb.Tree { return c.elems.Load() }
@@ -0,0 +1,61 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+type key int
+
+func (k key) Compare(elem llrb.Element) int { return int(k) - int(elem.(key)) }
+
+func keys(tree *llrb.Tree) []key {
+	var keys []key
+	tree.ForEach(func(elem llrb.Element) bool {
+		keys = append(keys, elem.(key))
+		return false
+	})
+	return keys
+}
+
+func TestCache(t *testing.T) {
+	c := New(3)
+	for i := key(0); i < 3; i++ {
+		if evicted := c.Put(i); evicted != nil {
+			t.Fatalf("put: unexpected eviction of %v", evicted)
+		}
+	}
+	if c.Get(key(0)) != key(0) || c.Get(key(42)) != nil {
+		t.Fatalf("get: unexpected result")
+	}
+	if evicted := c.Put(key(3)); evicted != key(1) {
+		t.Fatalf("put: expected eviction of 1, have %v", evicted)
+	}
+	if evicted := c.Put(key(0)); evicted != nil {
+		t.Fatalf("put: unexpected eviction of %v replacing an element", evicted)
+	}
+	snap := c.Snapshot()
+	if evicted := c.Put(key(4)); evicted != key(2) {
+		t.Fatalf("put: expected eviction of 2, have %v", evicted)
+	}
+	if have, want := keys(c.Snapshot()), []key{0, 3, 4}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("cache: expected %v, have %v", want, have)
+	}
+	if have, want := keys(snap), []key{0, 2, 3}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("snapshot: expected %v, have %v", want, have)
+	}
+
+	if !c.Delete(key(3)) || c.Delete(key(3)) || c.Len() != 2 {
+		t.Fatalf("delete: unexpected result")
+	}
+	c.Put(key(5))
+	if evicted := c.Put(key(6)); evicted != key(0) {
+		t.Fatalf("put: expected eviction of 0, have %v", evicted)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import "github.com/mars9/llrb"
+
+// stamp records the access of a key. seq increases with every access.
+type stamp struct {
+	key llrb.Element
+	seq uint64
+}
+
+// byKey orders stamps by key.
+type byKey stamp
+
+// Compare implements the llrb.Element interface.
+func (s byKey) Compare(elem llrb.Element) int {
+	return s.key.Compare(elem.(byKey).key)
+}
+
+// bySeq orders stamps by access.
+type bySeq stamp
+
+// Compare implements the llrb.Element interface.
+func (s bySeq) Compare(elem llrb.Element) int {
+	switch o := elem.(bySeq); {
+	case s.seq < o.seq:
+		return -1
+	case s.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+// Recency tracks the order in which keys were last accessed, so an
+// eviction policy can pick the least recently used key. Keys are
+// compared with their Compare method. All operations take time
+// logarithmic in the number of keys tracked. The zero Recency tracks no
+// keys. A Recency is not safe for concurrent use.
+type Recency struct {
+	seq   uint64
+	byKey *llrb.Tree // byKey stamps
+	bySeq *llrb.Tree // bySeq stamps of the same accesses
+}
+
+// Touch records an access of key, making it the most recently used.
+func (r *Recency) Touch(key llrb.Element) {
+	r.seq++
+	s := stamp{key: key, seq: r.seq}
+	keys, seqs := r.byKey.Txn(), r.bySeq.Txn()
+	if old, ok := keys.Get(byKey{key: key}).(byKey); ok {
+		seqs.Delete(bySeq(old))
+	}
+	keys.Insert(byKey(s))
+	seqs.Insert(bySeq(s))
+	r.byKey, r.bySeq = keys.Commit(), seqs.Commit()
+}
+
+// Remove stops tracking key and reports whether it was tracked.
+func (r *Recency) Remove(key llrb.Element) bool {
+	old, ok := r.byKey.Get(byKey{key: key}).(byKey)
+	if !ok {
+		return false
+	}
+	keys, seqs := r.byKey.Txn(), r.bySeq.Txn()
+	keys.Delete(old)
+	seqs.Delete(bySeq(old))
+	r.byKey, r.bySeq = keys.Commit(), seqs.Commit()
+	return true
+}
+
+// Oldest returns the least recently used key, or nil if no keys are
+// tracked.
+func (r *Recency) Oldest() llrb.Element {
+	if s, ok := r.bySeq.Min().(bySeq); ok {
+		return s.key
+	}
+	return nil
+}
+
+// Len returns the number of keys tracked.
+func (r *Recency) Len() int { return r.byKey.Len() }
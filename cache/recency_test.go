@@ -0,0 +1,30 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import "testing"
+
+func TestRecency(t *testing.T) {
+	var r Recency
+	if r.Oldest() != nil || r.Len() != 0 {
+		t.Fatalf("recency: expected no keys")
+	}
+	for i := key(0); i < 5; i++ {
+		r.Touch(i)
+	}
+	r.Touch(key(0))
+	r.Touch(key(2))
+	for _, want := range []key{1, 3, 4, 0, 2} {
+		if have := r.Oldest(); have != want {
+			t.Fatalf("recency: expected oldest %v, have %v", want, have)
+		}
+		if !r.Remove(want) {
+			t.Fatalf("recency: expected %v to be tracked", want)
+		}
+	}
+	if r.Len() != 0 || r.Remove(key(0)) {
+		t.Fatalf("recency: expected no keys")
+	}
+}
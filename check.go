@@ -0,0 +1,112 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+)
+
+// WithTxnChecks makes every transaction record the goroutine that
+// started it and panic when it is used by another goroutine or by two
+// goroutines at the same time. The checks are meant for debugging, they
+// add a considerable cost to each transaction operation.
+func WithTxnChecks() Option {
+	return func(t *Tree) {
+		t.checkTxn = true
+	}
+}
+
+// WithLeakDetection makes every transaction record the stack that
+// started it and report it through logf if the transaction is garbage
+// collected without Commit or Abort. A nil logf reports through
+// log.Printf. Recording the stack is expensive, so the option is meant
+// for debugging.
+func WithLeakDetection(logf func(format string, args ...interface{})) Option {
+	if logf == nil {
+		logf = log.Printf
+	}
+	return func(t *Tree) {
+		t.leakf = logf
+	}
+}
+
+// watchLeak records the stack of the caller starting t and arranges for
+// t to be reported if it leaks.
+func (t *Txn) watchLeak() {
+	t.created = debug.Stack()
+	logf := t.tree.leakf
+	runtime.SetFinalizer(t, func(t *Txn) {
+		logf("llrb: transaction garbage collected without Commit or Abort, started at:\n%s", t.created)
+	})
+}
+
+// unwatchLeak stops t from being reported once it is finished.
+func (t *Txn) unwatchLeak() {
+	if t.created != nil {
+		runtime.SetFinalizer(t, nil)
+		t.created = nil
+	}
+}
+
+type txnCheck struct {
+	owner int64
+	busy  int32
+}
+
+// enter marks the start of an operation on t, as own, and applies
+// pending appends and buffered inserts the operation may depend on.
+func (t *Txn) enter() {
+	t.own()
+	t.flush()
+}
+
+// own marks the start of an operation on t. If t is checked, it panics
+// if t is not used by the goroutine that started it or if another
+// operation on t is in progress.
+func (t *Txn) own() {
+	c := t.check
+	if c == nil {
+		return
+	}
+	if g := goid(); g != c.owner {
+		panic(fmt.Sprintf("llrb: transaction started by goroutine %d used by goroutine %d", c.owner, g))
+	}
+	if !atomic.CompareAndSwapInt32(&c.busy, 0, 1) {
+		panic(fmt.Sprintf("llrb: transaction started by goroutine %d used concurrently", c.owner))
+	}
+}
+
+// leave marks the end of an operation started by enter or own, after
+// checking the tree if strict checks are enabled.
+func (t *Txn) leave() {
+	if t.tree.strict {
+		t.verify()
+	}
+	if t.check != nil {
+		atomic.StoreInt32(&t.check.busy, 0)
+	}
+}
+
+// goid returns the id of the calling goroutine, as printed in stack
+// traces.
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		panic("llrb: cannot determine goroutine id")
+	}
+	return id
+}
@@ -0,0 +1,99 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTxnChecks(t *testing.T) {
+	tree := New(WithTxnChecks())
+	txn := tree.Txn()
+	for i := compInt(0); i < 10; i++ {
+		txn.Insert(i)
+	}
+	if err := txn.TryInsert(compRune('a')); err == nil {
+		t.Fatalf("txn checks: expected compare error")
+	}
+	txn.Delete(compInt(0))
+	if txn.Len() != 9 {
+		t.Fatalf("txn checks: expected tree length 9, have %d", txn.Len())
+	}
+
+	msg := make(chan interface{})
+	go func() {
+		defer func() { msg <- recover() }()
+		txn.Insert(compInt(10))
+	}()
+	v, _ := (<-msg).(string)
+	if !strings.Contains(v, "used by goroutine") {
+		t.Fatalf("txn checks: expected foreign goroutine panic, got %q", v)
+	}
+
+	txn.check.busy = 1
+	func() {
+		defer func() {
+			v, _ := recover().(string)
+			if !strings.Contains(v, "used concurrently") {
+				t.Fatalf("txn checks: expected concurrent use panic, got %q", v)
+			}
+		}()
+		txn.Get(compInt(1))
+	}()
+	txn.check.busy = 0
+
+	if tree = txn.Commit(); tree.Len() != 9 {
+		t.Fatalf("txn checks: expected tree length 9, have %d", tree.Len())
+	}
+}
+
+func TestLeakDetection(t *testing.T) {
+	leaks := make(chan string, 10)
+	logf := func(format string, args ...interface{}) {
+		leaks <- fmt.Sprintf(format, args...)
+	}
+	tree := New(WithLeakDetection(logf))
+
+	txn := tree.Txn()
+	txn.Insert(compInt(1))
+	tree = txn.Commit()
+	txn = tree.Txn()
+	txn.Insert(compInt(2))
+	txn.Abort()
+	if tree.Len() != 1 || tree.Get(compInt(2)) != nil {
+		t.Fatalf("abort: expected unchanged tree, have %v", elements(tree))
+	}
+	leakTxn(tree)
+
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case msg := <-leaks:
+			if !strings.Contains(msg, "leakTxn") {
+				t.Fatalf("leak: expected creation stack, have %q", msg)
+			}
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+			if len(leaks) != 0 {
+				t.Fatalf("leak: finished transaction reported: %q", <-leaks)
+			}
+			return
+		case <-deadline:
+			t.Fatalf("leak: transaction not reported")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// leakTxn starts a transaction on tree and drops it.
+func leakTxn(tree *Tree) {
+	txn := tree.Txn()
+	txn.Insert(compInt(3))
+}
@@ -0,0 +1,142 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrClosed is returned when submitting work to a closed Committer.
+var ErrClosed = errors.New("llrb: closed")
+
+// A Committer applies functions submitted by many goroutines to the
+// tree held by an Atomic. A single goroutine takes submitted functions
+// from a queue and applies as many as are waiting, up to a batch size,
+// in one transaction, so concurrent writers share the cost of a commit
+// and never contend for the root. The Committer must be the only
+// writer of its Atomic.
+type Committer struct {
+	root     *Atomic
+	maxBatch int
+	reqs     chan commitReq
+
+	mu     sync.RWMutex // held for writing while closing reqs
+	closed bool
+	done   chan struct{}
+}
+
+type commitReq struct {
+	fn  func(*Txn) error
+	err chan error
+}
+
+// NewCommitter returns a Committer publishing to root that commits at
+// most maxBatch functions at once. A maxBatch of 0 or less means no
+// limit.
+func NewCommitter(root *Atomic, maxBatch int) *Committer {
+	c := &Committer{
+		root:     root,
+		maxBatch: maxBatch,
+		reqs:     make(chan commitReq, 64),
+		done:     make(chan struct{}),
+	}
+	go c.loop()
+	return c
+}
+
+// Submit queues fn to be applied to a transaction and waits until the
+// transaction has been committed. The returned error is the one
+// returned by fn, or an error describing a panic raised by fn. If fn
+// fails, its modifications are discarded while those of other
+// functions in the same batch are committed.
+func (c *Committer) Submit(fn func(*Txn) error) error {
+	return <-c.SubmitAsync(fn)
+}
+
+// SubmitAsync queues fn like Submit but returns immediately. The
+// returned channel receives the result of fn once its transaction has
+// been committed.
+func (c *Committer) SubmitAsync(fn func(*Txn) error) <-chan error {
+	req := commitReq{fn: fn, err: make(chan error, 1)}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		req.err <- ErrClosed
+		return req.err
+	}
+	c.reqs <- req
+	return req.err
+}
+
+// Close stops accepting new functions and returns once all queued
+// functions have been committed.
+func (c *Committer) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.reqs)
+	}
+	c.mu.Unlock()
+	<-c.done
+	return nil
+}
+
+func (c *Committer) loop() {
+	defer close(c.done)
+
+	var batch []commitReq
+	var errs []error
+	for req := range c.reqs {
+		batch = append(batch[:0], req)
+	fill:
+		for c.maxBatch <= 0 || len(batch) < c.maxBatch {
+			select {
+			case req, ok := <-c.reqs:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, req)
+			default:
+				break fill
+			}
+		}
+
+		txn := c.root.Load().Txn()
+		errs = errs[:0]
+		for _, req := range batch {
+			errs = append(errs, apply(txn, req.fn))
+		}
+		c.root.Store(txn.Commit())
+		for i, req := range batch {
+			req.err <- errs[i]
+		}
+	}
+}
+
+// apply calls fn with txn. If fn fails, all its modifications are
+// undone.
+func apply(txn *Txn, fn func(*Txn) error) (err error) {
+	rollback := txn.savepoint()
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("llrb: submitted function panicked: %v", v)
+		}
+		if err != nil {
+			rollback()
+		}
+	}()
+	return fn(txn)
+}
+
+// savepoint returns a function that restores t to its current state.
+func (t *Txn) savepoint() func() {
+	txn, tree := *t, *t.tree
+	return func() {
+		*t = txn
+		*t.tree = tree
+	}
+}
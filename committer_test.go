@@ -0,0 +1,75 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestCommitter(t *testing.T) {
+	root := &Atomic{}
+	c := NewCommitter(root, 16)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				v := compInt(g*100 + i)
+				if i%2 == 0 {
+					if err := c.Submit(func(txn *Txn) error {
+						txn.Insert(v)
+						return nil
+					}); err != nil {
+						t.Errorf("committer: unexpected error %v", err)
+					}
+				} else {
+					<-c.SubmitAsync(func(txn *Txn) error {
+						txn.Insert(v)
+						return nil
+					})
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	errFailed := errors.New("failed")
+	if err := c.Submit(func(txn *Txn) error {
+		txn.Delete(compInt(0))
+		return errFailed
+	}); err != errFailed {
+		t.Fatalf("committer: expected %v, got %v", errFailed, err)
+	}
+	if err := c.Submit(func(txn *Txn) error {
+		txn.Delete(compInt(1))
+		panic("boom")
+	}); err == nil {
+		t.Fatalf("committer: expected error for panicking function")
+	}
+
+	async := c.SubmitAsync(func(txn *Txn) error {
+		txn.Insert(compInt(800))
+		return nil
+	})
+	c.Close()
+	if err := <-async; err != nil {
+		t.Fatalf("committer: unexpected error %v", err)
+	}
+	if err := c.Submit(func(*Txn) error { return nil }); err != ErrClosed {
+		t.Fatalf("committer: expected %v, got %v", ErrClosed, err)
+	}
+
+	tree := root.Load()
+	if tree.Len() != 801 {
+		t.Fatalf("committer: expected tree length 801, have %d", tree.Len())
+	}
+	if !tree.isBST() || !tree.isBalanced() || !tree.is23() {
+		t.Fatalf("committer: invalid tree")
+	}
+}
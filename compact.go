@@ -0,0 +1,65 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// WithCompaction makes Commit rebuild the tree with Compact once the
+// number of deletions since the tree was last built exceeds ratio times
+// the number of stored elements.
+func WithCompaction(ratio float64) Option {
+	return func(t *Tree) {
+		if ratio > 0 {
+			t.compactRatio = ratio
+		}
+	}
+}
+
+// WithConsolidation makes Commit rebuild the tree with Compact when the
+// transaction copied more than ratio times the number of stored
+// elements in nodes, that is, when it rewrote a large part of the tree
+// anyway. The rebuilt tree is balanced and allocated in sort order
+// instead of keeping the node graph produced by the individual
+// modifications.
+func WithConsolidation(ratio float64) Option {
+	return func(t *Tree) {
+		if ratio > 0 {
+			t.consolidateRatio = ratio
+		}
+	}
+}
+
+// Compact returns a copy of the tree rebuilt from scratch. The copy is
+// perfectly balanced, is allocated in sort order and shares no nodes
+// with the tree or any other version, which restores locality after
+// heavy churn.
+func (t *Tree) Compact() *Tree {
+	tree := &Tree{}
+	if t == nil {
+		return tree
+	}
+	*tree = *t
+	tree.deletes = 0
+	if t.root == nil {
+		return tree
+	}
+
+	elems := make([]Element, 0, t.size)
+	t.root.do(func(elem Element) bool {
+		elems = append(elems, elem)
+		return false
+	})
+	tree.root = build(elems, tree.aug)
+	return tree
+}
+
+func (t *Tree) needsCompaction() bool {
+	return t.compactRatio > 0 && float64(t.deletes) > t.compactRatio*float64(t.size)
+}
+
+// needsConsolidation reports whether the transaction rewrote enough of
+// the tree since it was last consolidated to rebuild it at Commit.
+func (t *Txn) needsConsolidation() bool {
+	r := t.tree.consolidateRatio
+	return r > 0 && float64(t.copied-t.consolidated) > r*float64(t.tree.size)
+}
@@ -0,0 +1,121 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	for n := 0; n <= 1000; n++ {
+		elems := make([]Element, n)
+		for i := range elems {
+			elems[i] = compInt(i)
+		}
+		tree := &Tree{root: build(elems, 0), size: n}
+		if !tree.isBST() {
+			t.Fatalf("build: tree of %d elements is not a BST", n)
+		}
+		if !tree.isBalanced() {
+			t.Fatalf("build: tree of %d elements is not balanced", n)
+		}
+		if !tree.isSized() {
+			t.Fatalf("build: tree of %d elements has inconsistent sizes", n)
+		}
+		if !tree.is23() {
+			t.Fatalf("build: tree of %d elements is not a 2-3 tree", n)
+		}
+		if n > 0 && !reflect.DeepEqual(elems, elements(tree)) {
+			t.Fatalf("build: expected values %v, have %v", elems, elements(tree))
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 1000; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+
+	compact := tree.Compact()
+	if !reflect.DeepEqual(elements(tree), elements(compact)) {
+		t.Fatalf("compact: elements differ from source tree")
+	}
+	if n := LiveNodes(tree, compact); n != 2*tree.Len() {
+		t.Fatalf("compact: expected no shared nodes, have %d live nodes", n)
+	}
+
+	txn = compact.Txn()
+	txn.Insert(compInt(1000))
+	txn.Delete(compInt(0))
+	compact = txn.Commit()
+	if compact.Len() != 1000 || !compact.isBST() || !compact.isBalanced() || !compact.is23() {
+		t.Fatalf("compact: invalid tree after modification")
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	tree := New(WithCompaction(0.5))
+	txn := tree.Txn()
+	for i := compInt(0); i < 100; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+
+	for i := compInt(0); i < 33; i++ {
+		txn = tree.Txn()
+		txn.Delete(i)
+		tree = txn.Commit()
+	}
+	if tree.deletes != 33 {
+		t.Fatalf("compaction: expected 33 deletions, have %d", tree.deletes)
+	}
+
+	txn = tree.Txn()
+	txn.Delete(compInt(33))
+	tree = txn.Commit()
+	if tree.deletes != 0 {
+		t.Fatalf("compaction: expected tree to be rebuilt, have %d deletions", tree.deletes)
+	}
+	if tree.Len() != 66 || tree.Min() != compInt(34) {
+		t.Fatalf("compaction: unexpected tree contents after rebuild")
+	}
+}
+
+func TestConsolidation(t *testing.T) {
+	tree := New(WithConsolidation(2))
+	txn := tree.Txn()
+	for i := compInt(0); i < 1000; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+	if n := LiveNodes(tree); n != tree.Len() {
+		t.Fatalf("consolidation: expected %d live nodes, have %d", tree.Len(), n)
+	}
+
+	// A small transaction keeps sharing nodes with its source.
+	txn = tree.Txn()
+	txn.Insert(compInt(1000))
+	next := txn.Commit()
+	if n := LiveNodes(tree, next); n >= 2*tree.Len() {
+		t.Fatalf("consolidation: expected shared nodes, have %d live nodes", n)
+	}
+
+	// Rewriting most of the tree rebuilds it.
+	txn = next.Txn()
+	for i := compInt(0); i < 1000; i += 2 {
+		txn.Delete(i)
+	}
+	next = txn.Commit()
+	if n := LiveNodes(tree, next); n != tree.Len()+next.Len() {
+		t.Fatalf("consolidation: expected no shared nodes, have %d live nodes", n)
+	}
+	if next.Len() != 501 || !next.isBST() || !next.isBalanced() || !next.is23() || !next.isSized() {
+		t.Fatalf("consolidation: invalid tree after rebuild")
+	}
+}
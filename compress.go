@@ -0,0 +1,79 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+)
+
+// errInflated is returned by Deflate.Decompress for payloads that
+// decompress to more than the largest block accepted by Restore.
+var errInflated = errors.New("llrb: decompressed block too large")
+
+// Compressor is implemented by Codecs that compress snapshots. Each
+// block of a snapshot is compressed on its own. Restore rejects blocks
+// that decompress to more than 1 GiB, and Decompress should stop with
+// an error once its output exceeds that. A Codec can gain compression
+// by embedding a Compressor such as Deflate:
+//
+//	type codec struct {
+//		myCodec
+//		llrb.Deflate
+//	}
+type Compressor interface {
+	// Compress appends the compressed src to dst.
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress appends the decompressed src to dst.
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+// Deflate is a Compressor using raw DEFLATE at the given compression
+// level, where 0 selects flate.DefaultCompression.
+type Deflate struct {
+	Level int
+}
+
+// Compress implements the Compressor interface.
+func (d Deflate) Compress(dst, src []byte) ([]byte, error) {
+	level := d.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	buf := bytes.NewBuffer(dst)
+	w, err := flate.NewWriter(buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress implements the Compressor interface.
+func (Deflate) Decompress(dst, src []byte) ([]byte, error) {
+	return inflate(dst, src, snapshotMaxBlock)
+}
+
+// inflate appends the decompressed src to dst, failing with errInflated
+// if it is longer than limit bytes.
+func inflate(dst, src []byte, limit int64) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	r := flate.NewReader(bytes.NewReader(src))
+	n, err := io.Copy(buf, io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > limit {
+		return nil, errInflated
+	}
+	return buf.Bytes(), r.Close()
+}
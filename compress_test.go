@@ -0,0 +1,59 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type deflateCodec struct {
+	intCodec
+	Deflate
+}
+
+func TestCompressedSnapshot(t *testing.T) {
+	tree := randomTree(50000, 60000)
+	var plain, compressed bytes.Buffer
+	if err := tree.Persist(&plain, intCodec{}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if err := tree.Persist(&compressed, deflateCodec{}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if compressed.Len() >= plain.Len()/2 {
+		t.Fatalf("persist: expected compression, have %d bytes for %d", compressed.Len(), plain.Len())
+	}
+	enc := compressed.Bytes()
+
+	restored, err := Restore(bytes.NewReader(enc), deflateCodec{})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !reflect.DeepEqual(elements(tree), elements(restored)) {
+		t.Fatalf("restore: restored tree differs")
+	}
+	if rep := VerifySnapshot(bytes.NewReader(enc), deflateCodec{}, false); rep.Err != nil || rep.Elements != tree.Len() {
+		t.Fatalf("verify: unexpected report %+v", rep)
+	}
+	if _, err := Restore(bytes.NewReader(enc), intCodec{}); !errors.Is(err, ErrSnapshot) {
+		t.Fatalf("restore: expected ErrSnapshot without Compressor, have %v", err)
+	}
+}
+
+func TestDecompressLimit(t *testing.T) {
+	src, err := Deflate{}.Compress(nil, make([]byte, 1<<20))
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if out, err := inflate(nil, src, 1<<20); err != nil || len(out) != 1<<20 {
+		t.Fatalf("inflate: expected %d bytes, have %d and %v", 1<<20, len(out), err)
+	}
+	if _, err := inflate(nil, src, 1<<20-1); err != errInflated {
+		t.Fatalf("inflate: expected %v, have %v", errInflated, err)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrDecrypt is returned when reading an encrypted snapshot that was
+// modified, truncated or encrypted with a different key.
+var ErrDecrypt = errors.New("llrb: snapshot decryption failed")
+
+// Encrypted snapshots start with a random salt, from which a key for
+// the file is derived with HKDF-SHA256, followed by chunks of up to
+// cryptChunkSize bytes of plaintext sealed with AES-256-GCM. The nonce
+// of a chunk is its sequence number followed by a byte marking the last
+// chunk, so chunks can be neither reordered nor dropped.
+const (
+	cryptSaltSize  = 32
+	cryptChunkSize = 64 << 10
+	cryptInfo      = "llrb snapshot"
+)
+
+// EncryptSnapshot returns a writer encrypting everything written to it
+// with key before passing it on to w, typically to be used as the
+// writer of Persist. The writer must be closed to write the final
+// chunk; closing does not close w. Key should be at least 16 bytes of
+// secret random data.
+func EncryptSnapshot(w io.Writer, key []byte) (io.WriteCloser, error) {
+	salt := make([]byte, cryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newSnapshotAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, buf: make([]byte, 0, cryptChunkSize)}, nil
+}
+
+// DecryptSnapshot returns a reader decrypting a snapshot encrypted by
+// EncryptSnapshot from r with the same key. Reads fail with ErrDecrypt
+// if the data does not authenticate.
+func DecryptSnapshot(r io.Reader, key []byte) (io.Reader, error) {
+	salt := make([]byte, cryptSaltSize)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, ErrDecrypt
+	}
+	aead, err := newSnapshotAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: bufio.NewReader(r), aead: aead}, nil
+}
+
+func newSnapshotAEAD(key, salt []byte) (cipher.AEAD, error) {
+	if len(key) < 16 {
+		return nil, errors.New("llrb: snapshot key shorter than 16 bytes")
+	}
+	block, err := aes.NewCipher(deriveKey(key, salt))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey returns the 32 byte AES-256 key derived from key and salt
+// with HKDF-SHA256 (RFC 5869). A single block of the expand step yields
+// all 32 bytes.
+func deriveKey(key, salt []byte) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(key)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(cryptInfo))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+// chunkNonce returns the nonce of chunk number seq.
+func chunkNonce(nonce []byte, seq uint64, last bool) []byte {
+	clear(nonce)
+	binary.BigEndian.PutUint64(nonce[len(nonce)-9:], seq)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+type encryptWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte // plaintext of the current chunk
+	seq   uint64
+	nonce [12]byte
+	out   []byte
+	err   error
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data follows, as the
+		// last chunk is sealed differently.
+		if len(e.buf) == cryptChunkSize {
+			if e.err = e.seal(false); e.err != nil {
+				return 0, e.err
+			}
+		}
+		k := copy(e.buf[len(e.buf):cryptChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+k]
+		p = p[k:]
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	e.out = e.aead.Seal(e.out[:0], chunkNonce(e.nonce[:], e.seq, last), e.buf, nil)
+	e.seq++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(e.out)
+	return err
+}
+
+// Close writes the final chunk.
+func (e *encryptWriter) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.seal(true)
+	if e.err == nil {
+		e.err = errors.New("llrb: write to closed snapshot encrypter")
+		return nil
+	}
+	return e.err
+}
+
+type decryptReader struct {
+	r     *bufio.Reader
+	aead  cipher.AEAD
+	seq   uint64
+	nonce [12]byte
+	in    []byte
+	buf   []byte // decrypted, unread plaintext
+	done  bool   // last chunk read
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk.
+func (d *decryptReader) open() error {
+	size := cryptChunkSize + d.aead.Overhead()
+	if cap(d.in) < size {
+		d.in = make([]byte, size)
+	}
+	n, err := io.ReadFull(d.r, d.in[:size])
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		d.done = true
+	case err != nil:
+		return err
+	default:
+		// A full chunk is the last one if nothing follows it.
+		if _, err := d.r.Peek(1); err == io.EOF {
+			d.done = true
+		}
+	}
+	plain, err := d.aead.Open(d.in[:0], chunkNonce(d.nonce[:], d.seq, d.done), d.in[:n], nil)
+	if err != nil {
+		return ErrDecrypt
+	}
+	d.seq++
+	d.buf = plain
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestEncryptSnapshot(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, n := range []int{0, 100, 20000} {
+		tree := randomTree(n, 4*n+1)
+		var buf bytes.Buffer
+		w, err := EncryptSnapshot(&buf, key)
+		if err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		if err := tree.Persist(w, intCodec{}); err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		enc := buf.Bytes()
+
+		r, err := DecryptSnapshot(bytes.NewReader(enc), key)
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		restored, err := Restore(r, intCodec{})
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		if !reflect.DeepEqual(elements(tree), elements(restored)) {
+			t.Fatalf("decrypt: restored tree differs")
+		}
+
+		for name, b := range map[string][]byte{
+			"truncated": enc[:len(enc)-1],
+			"flipped":   append(append([]byte(nil), enc[:40]...), append([]byte{enc[40] ^ 1}, enc[41:]...)...),
+		} {
+			r, err := DecryptSnapshot(bytes.NewReader(b), key)
+			if err == nil {
+				_, err = io.ReadAll(r)
+			}
+			if !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("decrypt %s: expected ErrDecrypt, have %v", name, err)
+			}
+		}
+		if n > 10000 {
+			// Dropping a whole trailing chunk must be detected too.
+			r, _ := DecryptSnapshot(bytes.NewReader(enc[:cryptSaltSize+cryptChunkSize+16]), key)
+			if _, err := io.ReadAll(r); !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("decrypt: expected ErrDecrypt for dropped chunk, have %v", err)
+			}
+		}
+	}
+
+	r, _ := DecryptSnapshot(bytes.NewReader(make([]byte, 100)), []byte("another key of 32 bytes........."))
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("decrypt: expected ErrDecrypt for wrong key, have %v", err)
+	}
+}
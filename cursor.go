@@ -0,0 +1,141 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"sync"
+	"time"
+)
+
+// A Cursor iterates over the elements of one version of a tree in
+// ascending order. It stays valid after newer versions have been
+// committed and keeps returning the elements of the version it was
+// created from; Stale reports whether that version has been superseded.
+type Cursor struct {
+	root   *node
+	stack  []*node  // nodes whose element and right subtree are pending
+	pooled *[]*node // stack taken from stackPool, nil if none
+}
+
+// stackPool holds the stacks of closed cursors.
+var stackPool = sync.Pool{
+	New: func() interface{} {
+		s := make([]*node, 0, 64)
+		return &s
+	},
+}
+
+// Cursor returns a Cursor positioned before the smallest element of the
+// tree. Its stack is taken from a pool and returned by Close, so that
+// short-lived cursors do not allocate one each.
+func (t *Tree) Cursor() *Cursor {
+	c := &Cursor{pooled: stackPool.Get().(*[]*node)}
+	c.stack = (*c.pooled)[:0]
+	if t != nil {
+		c.root = t.root
+	}
+	c.Seek(nil)
+	return c
+}
+
+// Close releases the stack of the cursor for reuse by other cursors.
+// The cursor must not be used afterwards. Closing a cursor is optional;
+// the stack of a cursor that is not closed is garbage collected.
+func (c *Cursor) Close() {
+	if c.pooled == nil {
+		return
+	}
+	s := c.stack[:cap(c.stack)]
+	clear(s) // do not keep nodes alive
+	*c.pooled = s[:0]
+	stackPool.Put(c.pooled)
+	c.root, c.stack, c.pooled = nil, nil, nil
+}
+
+// Seek positions the cursor before the first element not less than
+// elem. A nil elem positions it before the smallest element.
+func (c *Cursor) Seek(elem Element) {
+	c.stack = c.stack[:0]
+	for n := c.root; n != nil; {
+		if elem == nil || elem.Compare(n.elem) <= 0 {
+			c.stack = append(c.stack, n)
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+}
+
+// Next advances the cursor and returns the next element, or nil if all
+// elements have been returned.
+func (c *Cursor) Next() Element {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	n := c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+	for x := n.right; x != nil; x = x.left {
+		c.stack = append(c.stack, x)
+	}
+	return n.elem
+}
+
+// Stale reports whether current is a different version than the one
+// the cursor was created from. The check compares root nodes only, so
+// it is cheap, and a commit without modifications still counts as a
+// new version.
+func (c *Cursor) Stale(current *Tree) bool {
+	if current == nil {
+		return c.root != nil
+	}
+	return c.root != current.root
+}
+
+// RangeWithin is like Range but stops once the time budget d is used
+// up, so latency-sensitive callers can return partial results. The
+// deadline is checked after each element, so at least one element is
+// visited. If the budget ran out before all elements in [from, to) were
+// visited, rest is a cursor positioned before the first remaining one,
+// which can be resumed by its RangeWithin method; otherwise rest is nil.
+// A boolean is returned indicating whether the traversal was
+// interrupted by fn returning true.
+func (t *Tree) RangeWithin(d time.Duration, from, to Element, fn Visitor) (done bool, rest *Cursor) {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	c := t.Cursor()
+	c.Seek(from)
+	done, rest = c.RangeWithin(d, to, fn)
+	if rest == nil {
+		c.Close()
+	}
+	return done, rest
+}
+
+// RangeWithin performs fn on the elements from the position of the
+// cursor up to, but not including, to, within the time budget d, as
+// Tree.RangeWithin. A nil to leaves the end of the range open. If the
+// budget ran out first, rest is c.
+func (c *Cursor) RangeWithin(d time.Duration, to Element, fn Visitor) (done bool, rest *Cursor) {
+	deadline := time.Now().Add(d)
+	for c.more(to) {
+		if fn(c.Next()) {
+			return true, nil
+		}
+		if c.more(to) && !time.Now().Before(deadline) {
+			return false, c
+		}
+	}
+	return false, nil
+}
+
+// more reports whether the next element of the cursor is less than to.
+// A nil to is greater than all elements.
+func (c *Cursor) more(to Element) bool {
+	if len(c.stack) == 0 {
+		return false
+	}
+	return to == nil || to.Compare(c.stack[len(c.stack)-1].elem) > 0
+}
@@ -0,0 +1,127 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	if elem := (*Tree)(nil).Cursor().Next(); elem != nil {
+		t.Fatalf("cursor: expected no elements, got %v", elem)
+	}
+
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 100; i++ {
+		txn.Insert(2 * i)
+	}
+	tree = txn.Commit()
+
+	c := tree.Cursor()
+	var have []Element
+	for elem := c.Next(); elem != nil; elem = c.Next() {
+		have = append(have, elem)
+	}
+	if want := elements(tree); !reflect.DeepEqual(want, have) {
+		t.Fatalf("cursor: expected values %v, have %v", want, have)
+	}
+
+	c.Seek(compInt(51))
+	for i := compInt(52); i < 60; i += 2 {
+		if elem := c.Next(); elem != i {
+			t.Fatalf("cursor: expected element %v, got %v", i, elem)
+		}
+	}
+	if c.Stale(tree) {
+		t.Fatalf("cursor: unexpected stale cursor")
+	}
+
+	txn = tree.Txn()
+	txn.Delete(compInt(60))
+	next := txn.Commit()
+	if !c.Stale(next) {
+		t.Fatalf("cursor: expected stale cursor")
+	}
+	if elem := c.Next(); elem != compInt(60) {
+		t.Fatalf("cursor: expected element of original version %v, got %v", compInt(60), elem)
+	}
+	c.Seek(compInt(1000))
+	if elem := c.Next(); elem != nil {
+		t.Fatalf("cursor: expected no elements, got %v", elem)
+	}
+}
+
+func TestRangeWithin(t *testing.T) {
+	tree := randomTree(1000, 4000)
+	var want []Element
+	tree.Range(compInt(100), compInt(3000), func(elem Element) bool {
+		want = append(want, elem)
+		return false
+	})
+
+	var have []Element
+	visit := func(elem Element) bool {
+		have = append(have, elem)
+		return false
+	}
+	done, rest := tree.RangeWithin(0, compInt(100), compInt(3000), visit)
+	if done || rest == nil || len(have) != 1 {
+		t.Fatalf("range within: expected one element and a cursor, have %v, %v", have, rest)
+	}
+	for calls := 1; rest != nil; calls++ {
+		if calls > len(want) {
+			t.Fatalf("range within: no progress after %d calls", calls)
+		}
+		done, rest = rest.RangeWithin(time.Microsecond, compInt(3000), visit)
+	}
+	if done || !reflect.DeepEqual(want, have) {
+		t.Fatalf("range within: expected values %v, have %v", want, have)
+	}
+
+	have = nil
+	if done, rest = tree.RangeWithin(time.Hour, compInt(100), compInt(3000), visit); done || rest != nil {
+		t.Fatalf("range within: expected complete traversal, have %v, %v", done, rest)
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Fatalf("range within: expected values %v, have %v", want, have)
+	}
+	done, rest = tree.RangeWithin(time.Hour, compInt(100), compInt(3000), func(Element) bool { return true })
+	if !done || rest != nil {
+		t.Fatalf("range within: expected interrupted traversal, have %v, %v", done, rest)
+	}
+}
+
+func TestCursorClose(t *testing.T) {
+	tree := randomTree(1000, 5000)
+	want := elements(tree)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		c := tree.Cursor()
+		c.Seek(want[500])
+		for i := 0; i < 10; i++ {
+			c.Next()
+		}
+		c.Close()
+	})
+	if allocs > 1 {
+		t.Fatalf("cursor close: expected at most 1 allocation per cursor, have %v", allocs)
+	}
+
+	c := tree.Cursor()
+	c.Close()
+	c.Close()
+	if c.Next() != nil {
+		t.Fatalf("cursor close: expected closed cursor to be exhausted")
+	}
+	c = tree.Cursor()
+	for i, w := range want {
+		if e := c.Next(); e != w {
+			t.Fatalf("cursor close: expected %v at %d from reused stack, have %v", w, i, e)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Digester is implemented by elements with an expensive Compare that can
+// provide a cheap ordered digest, for example the first eight bytes of a
+// string key as a big-endian uint64, for trees created with WithDigests.
+//
+// Digests must be consistent with Compare: if a.Digest() < b.Digest()
+// then a.Compare(b) < 0. All elements and queries used with a tree
+// keeping digests must implement Digester.
+type Digester interface {
+	Digest() uint64
+}
+
+// WithDigests makes every node keep the digest of its element, and Get,
+// Insert and Delete compare digests first, calling Compare only when
+// they are equal. It costs a Digester type assertion each time a node
+// is updated or compared.
+func WithDigests() Option {
+	return func(t *Tree) {
+		t.aug |= augDigest
+	}
+}
+
+// compareDigest compares the digest of elem with dig, returning 0 if
+// they are equal or elem is not a Digester.
+func compareDigest(elem Element, dig uint64) int {
+	if d, ok := elem.(Digester); ok {
+		switch v := d.Digest(); {
+		case v < dig:
+			return -1
+		case v > dig:
+			return 1
+		}
+	}
+	return 0
+}
+
+// digestOf returns the digest of elem, or 0 if it is not a Digester.
+func digestOf(elem Element) uint64 {
+	if d, ok := elem.(Digester); ok {
+		return d.Digest()
+	}
+	return 0
+}
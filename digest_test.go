@@ -0,0 +1,66 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// digestString is a string element with an eight byte prefix digest.
+type digestString string
+
+func (s digestString) Compare(elem Element) int {
+	return strings.Compare(string(s), string(elem.(digestString)))
+}
+
+func (s digestString) Digest() uint64 {
+	var b [8]byte
+	copy(b[:], s)
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func TestDigest(t *testing.T) {
+	tree := New(WithStats(), WithDigests())
+	txn := tree.Txn()
+	var keys []digestString
+	for i := 0; i < 1000; i++ {
+		// Keys sharing their first eight bytes must fall back to Compare.
+		k := digestString(fmt.Sprintf("%06d-%04d", i/4, i))
+		keys = append(keys, k)
+		txn.Insert(k)
+	}
+	tree = txn.Commit()
+	if !tree.isBST() || !tree.is23() || !tree.isBalanced() || !tree.isSized() {
+		t.Fatalf("digest: tree invariants violated")
+	}
+
+	before := tree.Stats().Get
+	for _, k := range keys {
+		if e := tree.Get(k); e != k {
+			t.Fatalf("digest: expected %q, have %v", k, e)
+		}
+		if e := tree.Get(k + "x"); e != nil {
+			t.Fatalf("digest: unexpected match %v for %q", e, k+"x")
+		}
+	}
+	after := tree.Stats().Get
+	// Only nodes sharing a digest with the query need a Compare call, at
+	// most four nodes on any path rather than the full depth.
+	if compares := after.Compares - before.Compares; compares > 2*4*uint64(len(keys)) {
+		t.Fatalf("digest: expected few Compare calls, have %d", compares)
+	}
+
+	txn = tree.Txn()
+	for _, k := range keys[:500] {
+		txn.Delete(k)
+	}
+	tree = txn.Commit()
+	if tree.Len() != 500 || tree.Get(keys[0]) != nil || tree.Get(keys[999]) != keys[999] {
+		t.Fatalf("digest: unexpected tree after deletes")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvertedRange is returned by TryRange if to is less than from.
+var ErrInvertedRange = errors.New("llrb: inverted range")
+
+// A CompareError records a panic raised by an Element's Compare method,
+// typically because it was handed an element of an unexpected type.
+type CompareError struct {
+	Elem  Element     // element the operation was called with, or the range bound compared
+	Value interface{} // value passed to panic
+}
+
+func (e *CompareError) Error() string {
+	return fmt.Sprintf("llrb: compare %v: %v", e.Elem, e.Value)
+}
+
+// recoverCompare turns a panic raised while comparing elem into a
+// CompareError stored in err.
+func recoverCompare(elem Element, err *error) {
+	if v := recover(); v != nil {
+		*err = &CompareError{Elem: elem, Value: v}
+	}
+}
+
+// TryGet is like Get but returns an error instead of panicking if
+// Compare panics.
+func (t *Tree) TryGet(elem Element) (e Element, err error) {
+	defer recoverCompare(elem, &err)
+	return t.Get(elem), nil
+}
+
+// TryRange is like Range but returns an error instead of panicking if
+// to is less than from or if Compare panics, in which case the
+// CompareError holds the bound being compared. Panics raised by fn are
+// not recovered.
+func (t *Tree) TryRange(from, to Element, fn Visitor) (done bool, err error) {
+	visiting, cur := false, from
+	defer func() {
+		if visiting {
+			return
+		}
+		if v := recover(); v != nil {
+			err = &CompareError{Elem: cur, Value: v}
+		}
+	}()
+
+	if t == nil || t.root == nil {
+		return false, nil
+	}
+	// Compare each bound with a stored element before comparing the
+	// bounds with each other, so that a bound of the wrong type is
+	// caught by its own comparison.
+	from.Compare(t.root.elem)
+	cur = to
+	to.Compare(t.root.elem)
+	if from.Compare(to) > 0 {
+		return false, ErrInvertedRange
+	}
+	lo, hi := bound{from, &cur}, bound{to, &cur}
+	return t.root.doRange(lo, hi, func(elem Element) bool {
+		visiting = true
+		done := fn(elem)
+		visiting = false
+		return done
+	}), nil
+}
+
+// bound is a range bound that records itself in cur before each
+// comparison, so that a panic can be attributed to it.
+type bound struct {
+	Element
+	cur *Element
+}
+
+func (b bound) Compare(elem Element) int {
+	*b.cur = b.Element
+	return b.Element.Compare(elem)
+}
+
+// TryGet is like Get but returns an error instead of panicking if
+// Compare panics. Pending elements are merged first, as by TryInsert.
+func (t *Txn) TryGet(elem Element) (Element, error) {
+	t.enter()
+	defer t.leave()
+
+	return t.tree.TryGet(elem)
+}
+
+// TryInsert is like Insert but returns an error instead of panicking if
+// Compare panics or a transaction limit has been reached. The
+// transaction is left unchanged in that case. Elements pending from
+// Append or BufferInsert are merged into the tree first, and Compare
+// panics raised while merging them are not recovered.
+func (t *Txn) TryInsert(elem Element) (err error) {
+	if err := t.admit(); err != nil {
+		return err
+	}
+	t.settle()
+	defer recoverCompare(elem, &err)
+	t.Insert(elem)
+	return nil
+}
+
+// TryDelete is like Delete but returns an error instead of panicking if
+// Compare panics or a transaction limit has been reached. The
+// transaction is left unchanged in that case. Pending elements are
+// merged first, as by TryInsert.
+func (t *Txn) TryDelete(elem Element) (err error) {
+	if err := t.admit(); err != nil {
+		return err
+	}
+	t.settle()
+	defer recoverCompare(elem, &err)
+	t.Delete(elem)
+	return nil
+}
+
+// settle merges the elements pending from Append and BufferInsert into
+// the tree, so that a recovered panic cannot leave them partly merged.
+func (t *Txn) settle() {
+	t.enter()
+	t.leave()
+}
+
+// A VisitError records a panic raised by a Visitor passed to SafeRange
+// or SafeForEach.
+type VisitError struct {
+	Elem  Element     // element being visited
+	Pos   int         // number of elements visited before Elem
+	Value interface{} // value passed to panic
+}
+
+func (e *VisitError) Error() string {
+	return fmt.Sprintf("llrb: visit %v at position %d: %v", e.Elem, e.Pos, e.Value)
+}
+
+// SafeRange is like TryRange but also recovers panics raised by fn,
+// returning them as a *VisitError.
+func (t *Tree) SafeRange(from, to Element, fn Visitor) (done bool, err error) {
+	v := visitGuard{fn: fn}
+	defer v.recover(&err)
+	return t.TryRange(from, to, v.visit)
+}
+
+// SafeForEach is like ForEach but recovers panics raised by fn,
+// returning them as a *VisitError.
+func (t *Tree) SafeForEach(fn Visitor) (done bool, err error) {
+	v := visitGuard{fn: fn}
+	defer v.recover(&err)
+	return t.ForEach(v.visit), nil
+}
+
+// visitGuard tracks the element a Visitor is called with, so that a
+// panic raised by it can be located.
+type visitGuard struct {
+	fn   Visitor
+	elem Element
+	pos  int
+	busy bool
+}
+
+func (v *visitGuard) visit(elem Element) bool {
+	v.elem, v.busy = elem, true
+	done := v.fn(elem)
+	v.busy = false
+	v.pos++
+	return done
+}
+
+// recover turns a panic raised by the Visitor into a VisitError stored
+// in err. Other panics are passed on.
+func (v *visitGuard) recover(err *error) {
+	if !v.busy {
+		return
+	}
+	r := recover()
+	*err = &VisitError{Elem: v.elem, Pos: v.pos, Value: r}
+}
@@ -0,0 +1,134 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTryOperations(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 100; i++ {
+		if err := txn.TryInsert(i); err != nil {
+			t.Fatalf("try insert: unexpected error %v", err)
+		}
+	}
+	if err := txn.TryInsert(compRune('a')); err == nil {
+		t.Fatalf("try insert: expected compare error")
+	} else if cerr, ok := err.(*CompareError); !ok || cerr.Elem != compRune('a') {
+		t.Fatalf("try insert: unexpected error %#v", err)
+	}
+	if err := txn.TryDelete(compRune('a')); err == nil {
+		t.Fatalf("try delete: expected compare error")
+	}
+	if err := txn.TryDelete(compInt(0)); err != nil {
+		t.Fatalf("try delete: unexpected error %v", err)
+	}
+	if elem, err := txn.TryGet(compInt(1)); elem != compInt(1) || err != nil {
+		t.Fatalf("try get: expected element %v, got %v (%v)", compInt(1), elem, err)
+	}
+	txn.Append(compInt(100))
+	if err := txn.TryInsert(compRune('a')); err == nil {
+		t.Fatalf("try insert: expected compare error")
+	}
+	if txn.Get(compInt(100)) == nil {
+		t.Fatalf("try insert: pending append lost")
+	}
+	txn.Delete(compInt(100))
+	txn.Append(compInt(101))
+	if elem, err := txn.TryGet(compInt(101)); elem != compInt(101) || err != nil {
+		t.Fatalf("try get: expected appended element %v, got %v (%v)", compInt(101), elem, err)
+	}
+	txn.BufferInsert(compInt(102))
+	if elem, err := txn.TryGet(compInt(102)); elem != compInt(102) || err != nil {
+		t.Fatalf("try get: expected buffered element %v, got %v (%v)", compInt(102), elem, err)
+	}
+	txn.Delete(compInt(101))
+	txn.Delete(compInt(102))
+	tree = txn.Commit()
+	if tree.Len() != 99 || !tree.isBST() || !tree.isBalanced() || !tree.is23() {
+		t.Fatalf("try insert: invalid tree after recovered panics")
+	}
+
+	if _, err := tree.TryGet(compRune('a')); err == nil {
+		t.Fatalf("try get: expected compare error")
+	}
+	if _, err := tree.TryRange(compInt(10), compInt(5), nil); err != ErrInvertedRange {
+		t.Fatalf("try range: expected %v, got %v", ErrInvertedRange, err)
+	}
+	if _, err := tree.TryRange(compRune('a'), compRune('b'), nil); err == nil {
+		t.Fatalf("try range: expected compare error")
+	}
+	for _, r := range [][2]Element{{compInt(0), compRune('z')}, {compRune('a'), compInt(5)}} {
+		bad := r[1]
+		if _, ok := r[0].(compRune); ok {
+			bad = r[0]
+		}
+		if _, err := tree.TryRange(r[0], r[1], nil); err == nil {
+			t.Fatalf("try range: expected compare error for %v", r)
+		} else if cerr, ok := err.(*CompareError); !ok || cerr.Elem != bad {
+			t.Fatalf("try range: expected compare error for %v, have %#v", bad, err)
+		}
+	}
+
+	var result []Element
+	done, err := tree.TryRange(compInt(5), compInt(10), func(elem Element) bool {
+		result = append(result, elem)
+		return false
+	})
+	want := []Element{compInt(5), compInt(6), compInt(7), compInt(8), compInt(9)}
+	if done || err != nil || !reflect.DeepEqual(result, want) {
+		t.Fatalf("try range: expected values %v, have %v (%v)", want, result, err)
+	}
+}
+
+func TestTryRangeVisitorPanic(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	txn.Insert(compInt(1))
+	tree = txn.Commit()
+
+	defer func() {
+		if v := recover(); v != "visitor" {
+			t.Fatalf("try range: expected visitor panic, got %v", v)
+		}
+	}()
+	tree.TryRange(compInt(0), compInt(2), func(Element) bool { panic("visitor") })
+}
+
+func TestSafeTraversal(t *testing.T) {
+	tree := randomTree(100, 1000)
+	want := elements(tree)
+	fail := func(elem Element) bool {
+		if elem == want[42] {
+			panic("boom")
+		}
+		return false
+	}
+
+	done, err := tree.SafeForEach(fail)
+	verr, ok := err.(*VisitError)
+	if done || !ok || verr.Elem != want[42] || verr.Pos != 42 || verr.Value != "boom" {
+		t.Fatalf("safe for each: unexpected result %v, %#v", done, err)
+	}
+	done, err = tree.SafeRange(want[40], want[50], fail)
+	verr, ok = err.(*VisitError)
+	if done || !ok || verr.Elem != want[42] || verr.Pos != 2 {
+		t.Fatalf("safe range: unexpected result %v, %#v", done, err)
+	}
+
+	if _, err := tree.SafeRange(compRune('a'), compRune('b'), fail); err == nil {
+		t.Fatalf("safe range: expected compare error")
+	} else if _, ok := err.(*CompareError); !ok {
+		t.Fatalf("safe range: unexpected error %#v", err)
+	}
+	var n int
+	done, err = tree.SafeForEach(func(Element) bool { n++; return n == 10 })
+	if !done || err != nil || n != 10 {
+		t.Fatalf("safe for each: expected interrupted traversal, got %v, %v after %d", done, err, n)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Step is one comparison made while looking up an element.
+type Step struct {
+	Elem   Element // element of the node compared with
+	Depth  int     // depth of the node, the root being at depth 0
+	Color  Color   // color of the node
+	Cmp    int     // sign of the comparison of the looked up element with Elem
+	Digest bool    // comparison decided by Digester digests alone
+}
+
+// An Explanation describes how a lookup proceeded through the tree.
+type Explanation struct {
+	Elem     Element // element looked up
+	Filtered bool    // rejected by the Bloom filter without a traversal
+	Path     []Step  // comparisons in the order they were made
+	Found    Element // element matched, nil if none
+}
+
+// String returns the explanation as text, one comparison per line.
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "lookup %v:\n", e.Elem)
+	if e.Filtered {
+		b.WriteString("  rejected by bloom filter\n")
+	}
+	for _, s := range e.Path {
+		dir := "match"
+		switch {
+		case s.Cmp < 0:
+			dir = "go left"
+		case s.Cmp > 0:
+			dir = "go right"
+		}
+		by := ""
+		if s.Digest {
+			by = " by digest"
+		}
+		fmt.Fprintf(&b, "  %d: compare with %v (%v): %s%s\n", s.Depth, s.Elem, s.Color, dir, by)
+	}
+	if e.Found != nil {
+		fmt.Fprintf(&b, "  found %v\n", e.Found)
+	} else {
+		b.WriteString("  not found\n")
+	}
+	return b.String()
+}
+
+// Explain looks up elem like Get and returns the comparisons made on the
+// way, to help debugging Compare methods that fail to match elements
+// thought to be stored.
+func (t *Tree) Explain(elem Element) *Explanation {
+	e := &Explanation{Elem: elem}
+	if t == nil {
+		return e
+	}
+	if !t.bloom.mayContain(elem) {
+		e.Filtered = true
+		return e
+	}
+	for n, depth := t.root, 0; n != nil; depth++ {
+		s := Step{Elem: n.elem, Depth: depth, Color: Red}
+		if n.color == black {
+			s.Color = Black
+		}
+		switch c := (*probe)(nil).compare(elem, n); {
+		case c < 0:
+			s.Cmp = -1
+		case c > 0:
+			s.Cmp = 1
+		}
+		if n.kinds()&augDigest != 0 && compareDigest(elem, n.aug.digest) != 0 {
+			s.Digest = true
+		}
+		e.Path = append(e.Path, s)
+		switch {
+		case s.Cmp == 0:
+			e.Found = n.elem
+			return e
+		case s.Cmp < 0:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return e
+}
@@ -0,0 +1,54 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tree := randomTree(500, 2000)
+	for _, e := range elements(tree) {
+		x := tree.Explain(e)
+		if x.Found != e || len(x.Path) == 0 || x.Path[len(x.Path)-1].Cmp != 0 {
+			t.Fatalf("explain: expected %v to be found, have %v", e, x)
+		}
+		if x.Path[0].Elem != tree.root.elem || x.Path[0].Color != Black {
+			t.Fatalf("explain: expected path to start at the black root, have %v", x)
+		}
+		for i, s := range x.Path {
+			if s.Depth != i {
+				t.Fatalf("explain: expected depth %d, have %d", i, s.Depth)
+			}
+		}
+	}
+
+	x := tree.Explain(compInt(-1))
+	if x.Found != nil {
+		t.Fatalf("explain: unexpected match %v", x.Found)
+	}
+	for _, s := range x.Path {
+		if s.Cmp >= 0 {
+			t.Fatalf("explain: expected only left turns for -1, have %v", x)
+		}
+	}
+	if s := x.String(); !strings.Contains(s, "go left") || !strings.HasSuffix(s, "not found\n") {
+		t.Fatalf("explain: unexpected text %q", s)
+	}
+
+	keys := []digestString{"apple", "banana", "cherry"}
+	txn := New(WithDigests()).Txn()
+	for _, k := range keys {
+		txn.Insert(k)
+	}
+	x = txn.Commit().Explain(digestString("banana"))
+	if x.Found != digestString("banana") || x.Path[len(x.Path)-1].Digest {
+		t.Fatalf("explain: expected match by Compare, have %v", x)
+	}
+	if (*Tree)(nil).Explain(compInt(1)).Found != nil {
+		t.Fatalf("explain: unexpected match in nil tree")
+	}
+}
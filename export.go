@@ -0,0 +1,51 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "io"
+
+// A BatchWriter receives rows in batches. It matches the Write method
+// of the generic writer of the parquet-go module, so trees can be
+// written to Parquet files directly. An Arrow exporter can implement it
+// by appending each batch to an array.RecordBuilder and emitting the
+// resulting record batch.
+type BatchWriter[T any] interface {
+	Write(rows []T) (int, error)
+}
+
+// Export writes the elements of t in ascending order to w, mapped to
+// rows by fn, in batches of up to size rows. A size of 0 or less means
+// batches of 1024 rows. Export stops at the first error returned by fn
+// or w, and returns io.ErrShortWrite if w accepts fewer rows than it was
+// given.
+func Export[T any](t *Tree, w BatchWriter[T], size int, fn func(Element) (T, error)) error {
+	if size <= 0 {
+		size = 1024
+	}
+	batch := make([]T, 0, min(size, t.Len()))
+	flush := func() error {
+		n, err := w.Write(batch)
+		if err == nil && n < len(batch) {
+			err = io.ErrShortWrite
+		}
+		batch = batch[:0]
+		return err
+	}
+	var err error
+	t.ForEach(func(elem Element) bool {
+		var row T
+		if row, err = fn(elem); err != nil {
+			return true
+		}
+		if batch = append(batch, row); len(batch) == size {
+			err = flush()
+		}
+		return err != nil
+	})
+	if err != nil || len(batch) == 0 {
+		return err
+	}
+	return flush()
+}
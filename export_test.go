@@ -0,0 +1,67 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type intRow struct{ V int }
+
+// rowWriter records the batches written to it, accepting at most max
+// rows per batch if max is positive.
+type rowWriter struct {
+	batches [][]intRow
+	max     int
+}
+
+func (w *rowWriter) Write(rows []intRow) (int, error) {
+	w.batches = append(w.batches, append([]intRow(nil), rows...))
+	if w.max > 0 && len(rows) > w.max {
+		return w.max, nil
+	}
+	return len(rows), nil
+}
+
+func toRow(elem Element) (intRow, error) { return intRow{int(elem.(compInt))}, nil }
+
+func TestExport(t *testing.T) {
+	tree := randomTree(1000, 4000)
+	var want []intRow
+	for _, e := range elements(tree) {
+		want = append(want, intRow{int(e.(compInt))})
+	}
+
+	w := &rowWriter{}
+	if err := Export[intRow](tree, w, 300, toRow); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var have []intRow
+	for i, b := range w.batches {
+		if len(b) > 300 || (i < len(w.batches)-1 && len(b) != 300) {
+			t.Fatalf("export: unexpected batch size %d", len(b))
+		}
+		have = append(have, b...)
+	}
+	if len(w.batches) != 4 || !reflect.DeepEqual(want, have) {
+		t.Fatalf("export: expected %v in 4 batches, have %v in %d", want, have, len(w.batches))
+	}
+
+	if err := Export[intRow](tree, &rowWriter{max: 10}, 0, toRow); err != io.ErrShortWrite {
+		t.Fatalf("export: expected short write, have %v", err)
+	}
+	fail := errors.New("no row")
+	err := Export(tree, &rowWriter{}, 0, func(Element) (intRow, error) { return intRow{}, fail })
+	if err != fail {
+		t.Fatalf("export: expected mapping error, have %v", err)
+	}
+	w = &rowWriter{}
+	if err := Export[intRow](nil, w, 0, toRow); err != nil || len(w.batches) != 0 {
+		t.Fatalf("export: expected no batches for nil tree, have %d (%v)", len(w.batches), err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "math/bits"
+
+// Eytzinger is a read-only tree stored in an implicit binary search
+// layout. The children of the element at index k are found at 2k and
+// 2k+1, so a search touches a predictable sequence of slots and the
+// top levels of the tree share few cache lines. Large read-mostly
+// snapshots answer Get faster in this form than as a Frozen slice or a
+// node based Tree.
+type Eytzinger struct {
+	elems []Element // elems[0] is unused
+}
+
+// Eytzinger returns a copy of the frozen tree in Eytzinger order.
+func (f *Frozen) Eytzinger() *Eytzinger {
+	e := &Eytzinger{elems: make([]Element, len(f.elems)+1)}
+	var i int
+	var fill func(k int)
+	fill = func(k int) {
+		if k >= len(e.elems) {
+			return
+		}
+		fill(2 * k)
+		e.elems[k] = f.elems[i]
+		i++
+		fill(2*k + 1)
+	}
+	fill(1)
+	return e
+}
+
+// search returns the slot of the first element not less than elem, or
+// 0 if there is no such element.
+func (e *Eytzinger) search(elem Element) int {
+	k := 1
+	for k < len(e.elems) {
+		if elem.Compare(e.elems[k]) > 0 {
+			k = 2*k + 1
+		} else {
+			k = 2 * k
+		}
+	}
+	return k >> uint(bits.TrailingZeros(^uint(k))+1)
+}
+
+// next returns the slot following k in sort order, or 0 if k holds the
+// maximum.
+func (e *Eytzinger) next(k int) int {
+	if 2*k+1 < len(e.elems) {
+		for k = 2*k + 1; 2*k < len(e.elems); k *= 2 {
+		}
+		return k
+	}
+	for k&1 == 1 {
+		k >>= 1
+	}
+	return k >> 1
+}
+
+// Range performs fn on all values stored in the tree over the interval
+// [from, to) from left to right. If to is less than from Range will
+// panic. A boolean is returned indicating whether the Range traversal
+// was interrupted by an Visitor returning true.
+func (e *Eytzinger) Range(from, to Element, fn Visitor) bool {
+	if e.Len() == 0 {
+		return false
+	}
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	for k := e.search(from); k != 0; k = e.next(k) {
+		if to.Compare(e.elems[k]) <= 0 {
+			break
+		}
+		if fn(e.elems[k]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForEach performs fn on all values stored in the tree. A boolean is
+// returned indicating whether the ForEach traversal was interrupted by
+// a Visitor returning true.
+func (e *Eytzinger) ForEach(fn Visitor) bool {
+	if e.Len() == 0 {
+		return false
+	}
+	for k := e.first(); k != 0; k = e.next(k) {
+		if fn(e.elems[k]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the first match of elem in the tree.
+func (e *Eytzinger) Get(elem Element) Element {
+	k := e.search(elem)
+	if k != 0 && elem.Compare(e.elems[k]) == 0 {
+		return e.elems[k]
+	}
+	return nil
+}
+
+func (e *Eytzinger) first() int {
+	k := 1
+	for ; 2*k < len(e.elems); k *= 2 {
+	}
+	return k
+}
+
+// Max returns the maximum value stored in the tree.
+func (e *Eytzinger) Max() Element {
+	if e.Len() == 0 {
+		return nil
+	}
+	k := 1
+	for ; 2*k+1 < len(e.elems); k = 2*k + 1 {
+	}
+	return e.elems[k]
+}
+
+// Min returns the minimum value stored in the tree.
+func (e *Eytzinger) Min() Element {
+	if e.Len() == 0 {
+		return nil
+	}
+	return e.elems[e.first()]
+}
+
+// Len returns the number of elements stored in the tree.
+func (e *Eytzinger) Len() int { return len(e.elems) - 1 }
@@ -0,0 +1,104 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestEytzinger(t *testing.T) {
+	e := (&Tree{}).Freeze().Eytzinger()
+	if e.Len() != 0 || e.Min() != nil || e.Max() != nil || e.Get(compInt(1)) != nil {
+		t.Fatalf("eytzinger: expected empty tree, got %v", e.elems)
+	}
+
+	for _, n := range []int{1, 2, 3, 7, 8, 100, 1023} {
+		tree := &Tree{}
+		txn := tree.Txn()
+		for i := 0; i < n; i++ {
+			txn.Insert(compInt(2 * i))
+		}
+		tree = txn.Commit()
+		e = tree.Freeze().Eytzinger()
+
+		if e.Len() != n {
+			t.Fatalf("eytzinger: expected length %d, have %d", n, e.Len())
+		}
+		if e.Min() != tree.Min() || e.Max() != tree.Max() {
+			t.Fatalf("eytzinger: expected min/max %v/%v, have %v/%v",
+				tree.Min(), tree.Max(), e.Min(), e.Max())
+		}
+		for i := -1; i <= 2*n; i++ {
+			if e.Get(compInt(i)) != tree.Get(compInt(i)) {
+				t.Fatalf("eytzinger: expected element %v, got %v", tree.Get(compInt(i)), e.Get(compInt(i)))
+			}
+		}
+
+		var want, have []Element
+		collect := func(dst *[]Element) Visitor {
+			return func(elem Element) bool {
+				*dst = append(*dst, elem)
+				return false
+			}
+		}
+		tree.ForEach(collect(&want))
+		e.ForEach(collect(&have))
+		if !reflect.DeepEqual(want, have) {
+			t.Fatalf("eytzinger: expected values %v, have %v", want, have)
+		}
+
+		want, have = want[:0], have[:0]
+		tree.Range(compInt(n/2-1), compInt(n+1), collect(&want))
+		e.Range(compInt(n/2-1), compInt(n+1), collect(&have))
+		if !reflect.DeepEqual(want, have) {
+			t.Fatalf("eytzinger: expected range values %v, have %v", want, have)
+		}
+	}
+}
+
+const benchSize = 1 << 20
+
+func benchTree() *Tree {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := 0; i < benchSize; i++ {
+		txn.Insert(compInt(i))
+	}
+	return txn.Commit()
+}
+
+func benchKeys() []Element {
+	keys := make([]Element, 1024)
+	for i := range keys {
+		keys[i] = compInt(rand.Intn(benchSize))
+	}
+	return keys
+}
+
+func BenchmarkGetTree(b *testing.B) {
+	tree, keys := benchTree(), benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkGetFrozen(b *testing.B) {
+	f, keys := benchTree().Freeze(), benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkGetEytzinger(b *testing.B) {
+	e, keys := benchTree().Freeze().Eytzinger(), benchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Get(keys[i%len(keys)])
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+)
+
+// Fingerprint returns a 64-bit FNV-1a hash of the shape of the tree and
+// its elements. Two trees have the same fingerprint if they hold equal
+// elements in identically shaped and colored nodes, so golden tests can
+// assert that a change leaves committed structure alone, and bug reports
+// can name an exact tree. Elements implementing
+// encoding.BinaryMarshaler are hashed by their binary encoding, others
+// by their %v formatting; either must be stable for the fingerprint to
+// be. Fingerprint panics if MarshalBinary fails.
+func (t *Tree) Fingerprint() uint64 {
+	h := fnv.New64a()
+	var root *node
+	if t != nil {
+		root = t.root
+	}
+	root.fingerprint(h)
+	return h.Sum64()
+}
+
+// fingerprint writes the nodes of the subtree rooted at n to h in
+// pre-order, marking nil children, so the encoding determines the shape.
+func (n *node) fingerprint(h hash.Hash64) {
+	if n == nil {
+		h.Write([]byte{0})
+		return
+	}
+	tag := byte(1)
+	if n.color == black {
+		tag = 2
+	}
+	var b []byte
+	if m, ok := n.elem.(encoding.BinaryMarshaler); ok {
+		var err error
+		if b, err = m.MarshalBinary(); err != nil {
+			panic(err)
+		}
+	} else {
+		b = fmt.Append(nil, n.elem)
+	}
+	h.Write(binary.AppendUvarint([]byte{tag}, uint64(len(b))))
+	h.Write(b)
+	n.left.fingerprint(h)
+	n.right.fingerprint(h)
+}
@@ -0,0 +1,37 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	if (*Tree)(nil).Fingerprint() != (&Tree{}).Fingerprint() {
+		t.Fatalf("fingerprint: expected nil tree to match empty tree")
+	}
+
+	insert := func(elems ...compInt) *Tree {
+		txn := (&Tree{}).Txn()
+		for _, e := range elems {
+			txn.Insert(e)
+		}
+		return txn.Commit()
+	}
+	a := insert(1, 2, 3, 4, 5)
+	if a.Fingerprint() != insert(5, 4, 3, 2, 1).Fingerprint() {
+		t.Fatalf("fingerprint: expected identically shaped trees to match")
+	}
+	if a.Fingerprint() == insert(1, 2, 3, 4, 6).Fingerprint() {
+		t.Fatalf("fingerprint: expected different elements to differ")
+	}
+	built := fromElements([]Element{compInt(1), compInt(2), compInt(3), compInt(4), compInt(5)})
+	if a.Fingerprint() == built.Fingerprint() {
+		t.Fatalf("fingerprint: expected differently shaped trees to differ")
+	}
+
+	// Guards the encoding against accidental changes.
+	if have, want := insert(1, 2, 3).Fingerprint(), uint64(0x49fc8e1de36a3994); have != want {
+		t.Fatalf("fingerprint: expected %#x, have %#x", want, have)
+	}
+}
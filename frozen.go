@@ -0,0 +1,99 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "sort"
+
+// Frozen is a read-only, array-backed copy of a Tree. It offers the same
+// query methods as Tree, but stores elements in a single sorted slice
+// without per-node pointers, which gives better cache behavior for
+// trees that will never be modified again.
+type Frozen struct {
+	elems []Element
+}
+
+// Freeze returns a Frozen copy of the tree holding all of its elements
+// in sort order.
+func (t *Tree) Freeze() *Frozen {
+	f := &Frozen{}
+	if t == nil || t.root == nil {
+		return f
+	}
+	f.elems = make([]Element, 0, t.size)
+	t.root.do(func(elem Element) bool {
+		f.elems = append(f.elems, elem)
+		return false
+	})
+	return f
+}
+
+// search returns the index of the first element not less than elem.
+func (f *Frozen) search(elem Element) int {
+	return sort.Search(len(f.elems), func(i int) bool {
+		return elem.Compare(f.elems[i]) <= 0
+	})
+}
+
+// Range performs fn on all values stored in the frozen tree over the
+// interval [from, to) from left to right. If to is less than from Range
+// will panic. A boolean is returned indicating whether the Range
+// traversal was interrupted by an Visitor returning true.
+func (f *Frozen) Range(from, to Element, fn Visitor) bool {
+	if len(f.elems) == 0 {
+		return false
+	}
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	for i := f.search(from); i < len(f.elems); i++ {
+		if to.Compare(f.elems[i]) <= 0 {
+			break
+		}
+		if fn(f.elems[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForEach performs fn on all values stored in the frozen tree. A
+// boolean is returned indicating whether the ForEach traversal was
+// interrupted by a Visitor returning true.
+func (f *Frozen) ForEach(fn Visitor) bool {
+	for _, elem := range f.elems {
+		if fn(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the first match of elem in the frozen tree.
+func (f *Frozen) Get(elem Element) Element {
+	i := f.search(elem)
+	if i < len(f.elems) && elem.Compare(f.elems[i]) == 0 {
+		return f.elems[i]
+	}
+	return nil
+}
+
+// Max returns the maximum value stored in the frozen tree.
+func (f *Frozen) Max() Element {
+	if len(f.elems) == 0 {
+		return nil
+	}
+	return f.elems[len(f.elems)-1]
+}
+
+// Min returns the minimum value stored in the frozen tree.
+func (f *Frozen) Min() Element {
+	if len(f.elems) == 0 {
+		return nil
+	}
+	return f.elems[0]
+}
+
+// Len returns the number of elements stored in the frozen tree.
+func (f *Frozen) Len() int { return len(f.elems) }
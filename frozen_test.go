@@ -0,0 +1,60 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	f := (&Tree{}).Freeze()
+	if f.Len() != 0 || f.Min() != nil || f.Max() != nil || f.Get(compInt(1)) != nil {
+		t.Fatalf("freeze: expected empty frozen tree, got %v", f.elems)
+	}
+
+	values := compInts{-10, -32, 100, 46, 239, 2349, 101, 0, 1}
+	tree := &Tree{}
+	txn := tree.Txn()
+	for _, v := range values {
+		txn.Insert(v)
+	}
+	tree = txn.Commit()
+	f = tree.Freeze()
+	sort.Sort(values)
+
+	if f.Len() != len(values) {
+		t.Fatalf("freeze: expected length %d, have %d", len(values), f.Len())
+	}
+	if f.Min() != values[0] || f.Max() != values[len(values)-1] {
+		t.Fatalf("freeze: expected min/max %v/%v, have %v/%v",
+			values[0], values[len(values)-1], f.Min(), f.Max())
+	}
+	for _, v := range values {
+		if f.Get(v) != v {
+			t.Fatalf("freeze: expected element %v, got %v", v, f.Get(v))
+		}
+	}
+	if f.Get(compInt(2)) != nil {
+		t.Fatalf("freeze: unexpected element found %v", f.Get(compInt(2)))
+	}
+
+	var result compInts
+	v := func(elem Element) bool {
+		result = append(result, elem.(compInt))
+		return false
+	}
+	f.ForEach(v)
+	if !reflect.DeepEqual(values, result) {
+		t.Fatalf("freeze: expected values %v, have %v", values, result)
+	}
+	result = result[:0]
+
+	f.Range(compInt(-10), compInt(2), v)
+	if !reflect.DeepEqual(values[1:4], result) {
+		t.Fatalf("freeze: expected values %v, have %v", values[1:4], result)
+	}
+}
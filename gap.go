@@ -0,0 +1,157 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "math"
+
+// Positioned is implemented by elements that map to a point on the
+// integer line, such as offsets, IDs, ports or timestamps, for MaxGap,
+// ClosestPair and FreeSlot on trees created with WithGaps.
+type Positioned interface {
+	// Position returns the position of the element. Positions must
+	// increase strictly with the element order, the distance between
+	// any two of them must fit in an int64, and the position of a stored
+	// element must not change.
+	Position() int64
+}
+
+// positionOf returns the position of elem, or 0 if it is not
+// Positioned.
+func positionOf(elem Element) int64 {
+	if p, ok := elem.(Positioned); ok {
+		return p.Position()
+	}
+	return 0
+}
+
+// WithGaps makes every node keep the smallest and largest position in
+// its subtree and the smallest and largest distance between adjacent
+// positions, maintained through inserts and deletes like the subtree
+// size, so that MaxGap, ClosestPair and FreeSlot take time logarithmic
+// in the size of the tree. It costs a Positioned type assertion each
+// time a node is updated.
+func WithGaps() Option {
+	return func(t *Tree) {
+		t.aug |= augGap
+	}
+}
+
+// updateGap recomputes the position bounds and the gaps of the subtree
+// rooted at n from its children. A subtree of a single element has a
+// smallest gap of math.MaxInt64 and a largest gap of 0.
+func (n *node) updateGap() {
+	a, pos := n.aug, positionOf(n.elem)
+	a.lo, a.hi, a.minGap, a.maxGap = pos, pos, math.MaxInt64, 0
+	if l := n.left; l != nil {
+		a.lo = l.aug.lo
+		a.minGap = min(l.aug.minGap, pos-l.aug.hi)
+		a.maxGap = max(l.aug.maxGap, pos-l.aug.hi)
+	}
+	if r := n.right; r != nil {
+		a.hi = r.aug.hi
+		a.minGap = min(a.minGap, r.aug.minGap, r.aug.lo-pos)
+		a.maxGap = max(a.maxGap, r.aug.maxGap, r.aug.lo-pos)
+	}
+}
+
+// checkGaps panics if t holds elements but does not keep gaps.
+func (t *Tree) checkGaps() {
+	if t != nil && t.root != nil && t.root.kinds()&augGap == 0 {
+		panic("llrb: tree created without WithGaps")
+	}
+}
+
+// MaxGap returns the adjacent pair of elements whose positions are
+// farthest apart, or nil, nil if the tree holds fewer than two elements.
+// If several pairs are equally far apart, the first one is returned.
+// All elements must be Positioned, and MaxGap panics if t holds
+// elements but was not created with WithGaps.
+func (t *Tree) MaxGap() (lo, hi Element) {
+	t.checkGaps()
+	if t.Len() < 2 {
+		return nil, nil
+	}
+	return t.root.findGap(func(n *node) int64 { return n.aug.maxGap })
+}
+
+// ClosestPair returns the adjacent pair of elements whose positions are
+// nearest to each other, or nil, nil if the tree holds fewer than two
+// elements. If several pairs are equally near, the first one is
+// returned. All elements must be Positioned, and ClosestPair panics if
+// t holds elements but was not created with WithGaps.
+func (t *Tree) ClosestPair() (lo, hi Element) {
+	t.checkGaps()
+	if t.Len() < 2 {
+		return nil, nil
+	}
+	return t.root.findGap(func(n *node) int64 { return n.aug.minGap })
+}
+
+// findGap returns the first adjacent pair of elements in the subtree
+// rooted at n, which must hold at least two elements, whose distance is
+// the gap of n selected by gap.
+func (n *node) findGap(gap func(*node) int64) (lo, hi Element) {
+	for {
+		pos, want := positionOf(n.elem), gap(n)
+		l, r := n.left, n.right
+		switch {
+		case l.len() > 1 && gap(l) == want:
+			n = l
+		case l != nil && pos-l.aug.hi == want:
+			return l.max().elem, n.elem
+		case r != nil && r.aug.lo-pos == want:
+			return n.elem, r.min().elem
+		default:
+			n = r
+		}
+	}
+}
+
+// FreeSlot returns the smallest position p not less than start such that
+// no element is positioned in [p, p+size). Positions above the maximum
+// are free, so a slot is always found. All elements must be Positioned,
+// and FreeSlot panics if size is not positive or t holds elements but
+// was not created with WithGaps.
+func (t *Tree) FreeSlot(start, size int64) int64 {
+	if size <= 0 {
+		panic("llrb: non-positive slot size")
+	}
+	t.checkGaps()
+	if t == nil {
+		return start
+	}
+	p, ok, prev := t.root.freeSlot(size, start-1)
+	if ok {
+		return p
+	}
+	return prev + 1
+}
+
+// freeSlot returns the first slot of size free positions above prev
+// that ends before a position in the subtree rooted at n, where prev is
+// the largest position preceding the subtree or start-1, whichever is
+// larger. If there is no such slot, it returns false and the value of
+// prev following the subtree.
+func (n *node) freeSlot(size, prev int64) (int64, bool, int64) {
+	switch {
+	case n == nil || n.aug.hi <= prev:
+		return 0, false, prev
+	case n.aug.lo > prev && n.aug.lo-prev > size:
+		return prev + 1, true, prev
+	case n.aug.lo > prev && n.aug.maxGap <= size:
+		return 0, false, n.aug.hi
+	}
+	p, ok, prev := n.left.freeSlot(size, prev)
+	if ok {
+		return p, true, prev
+	}
+	if pos := positionOf(n.elem); pos > prev {
+		if pos-prev > size {
+			return prev + 1, true, prev
+		}
+		prev = pos
+	}
+	return n.right.freeSlot(size, prev)
+}
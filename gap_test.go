@@ -0,0 +1,178 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// slot is an element positioned at its value.
+type slot int64
+
+func (s slot) Compare(elem Element) int { return int(s - elem.(slot)) }
+func (s slot) Position() int64          { return int64(s) }
+
+func TestMaxGap(t *testing.T) {
+	tree := New(WithGaps())
+	if lo, hi := tree.MaxGap(); lo != nil || hi != nil {
+		t.Fatalf("max gap: expected nil for empty tree, have %v, %v", lo, hi)
+	}
+	txn := tree.Txn()
+	txn.Insert(slot(7))
+	if lo, hi := txn.Commit().MaxGap(); lo != nil || hi != nil {
+		t.Fatalf("max gap: expected nil for single element, have %v, %v", lo, hi)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		v := slot(rng.Intn(10000))
+		if rng.Intn(4) == 0 {
+			txn.Delete(v)
+		} else {
+			txn.Insert(v)
+		}
+		if i%100 != 0 {
+			continue
+		}
+		tree := txn.Commit()
+		if err := tree.Check(); err != nil {
+			t.Fatalf("max gap: %v", err)
+		}
+		var want [2]Element
+		var prev Element
+		tree.ForEach(func(elem Element) bool {
+			if prev != nil && (want[0] == nil || elem.(slot)-prev.(slot) > want[1].(slot)-want[0].(slot)) {
+				want = [2]Element{prev, elem}
+			}
+			prev = elem
+			return false
+		})
+		if lo, hi := tree.MaxGap(); lo != want[0] || hi != want[1] {
+			t.Fatalf("max gap: expected %v, %v, have %v, %v", want[0], want[1], lo, hi)
+		}
+	}
+
+	// A single appended element is joined onto the tree at a leaf.
+	tree = txn.Commit()
+	max := tree.Max()
+	txn = tree.Txn()
+	txn.Append(slot(1 << 20))
+	tree = txn.Commit()
+	if err := tree.Check(); err != nil {
+		t.Fatalf("max gap: %v", err)
+	}
+	if lo, hi := tree.MaxGap(); lo != max || hi != slot(1<<20) {
+		t.Fatalf("max gap: expected appended element, have %v, %v", lo, hi)
+	}
+}
+
+func TestClosestPair(t *testing.T) {
+	if lo, hi := (*Tree)(nil).ClosestPair(); lo != nil || hi != nil {
+		t.Fatalf("closest pair: expected nil for nil tree, have %v, %v", lo, hi)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	txn := New(WithGaps()).Txn()
+	for i := 0; i < 2000; i++ {
+		v := slot(rng.Intn(100000))
+		if rng.Intn(4) == 0 {
+			txn.Delete(v)
+		} else {
+			txn.Insert(v)
+		}
+		if i%100 != 0 {
+			continue
+		}
+		tree := txn.Commit()
+		var want [2]Element
+		var prev Element
+		tree.ForEach(func(elem Element) bool {
+			if prev != nil && (want[0] == nil || elem.(slot)-prev.(slot) < want[1].(slot)-want[0].(slot)) {
+				want = [2]Element{prev, elem}
+			}
+			prev = elem
+			return false
+		})
+		if lo, hi := tree.ClosestPair(); lo != want[0] || hi != want[1] {
+			t.Fatalf("closest pair: expected %v, %v, have %v, %v", want[0], want[1], lo, hi)
+		}
+	}
+}
+
+func TestFreeSlot(t *testing.T) {
+	if p := (*Tree)(nil).FreeSlot(5, 3); p != 5 {
+		t.Fatalf("free slot: expected 5 for nil tree, have %d", p)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	used := make(map[int64]bool)
+	txn := New(WithGaps()).Txn()
+	for i := 0; i < 300; i++ {
+		v := rng.Int63n(1000)
+		used[v] = true
+		txn.Insert(slot(v))
+	}
+	tree := txn.Commit()
+
+	free := func(start, size int64) int64 {
+		for p := start; ; p++ {
+			ok := true
+			for q := p; q < p+size; q++ {
+				if used[q] {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				return p
+			}
+		}
+	}
+	for i := 0; i < 2000; i++ {
+		start, size := rng.Int63n(1100)-50, rng.Int63n(8)+1
+		if p, want := tree.FreeSlot(start, size), free(start, size); p != want {
+			t.Fatalf("free slot: expected %d for start %d size %d, have %d", want, start, size, p)
+		}
+	}
+
+	// An allocator taking the first free ID fills the holes in order.
+	txn = tree.Txn()
+	for i, last := 0, int64(-1); i < 50; i++ {
+		p := txn.tree.FreeSlot(0, 1)
+		if used[p] || p <= last {
+			t.Fatalf("free slot: expected unused ID above %d, have %d", last, p)
+		}
+		used[p], last = true, p
+		txn.Insert(slot(p))
+	}
+	if err := txn.Commit().Check(); err != nil {
+		t.Fatalf("free slot: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("free slot: expected panic for size 0")
+		}
+	}()
+	tree.FreeSlot(0, 0)
+}
+
+func TestWithoutGaps(t *testing.T) {
+	txn := New().Txn()
+	for i := 0; i < 100; i++ {
+		txn.Insert(slot(i))
+	}
+	tree := txn.Commit()
+	if n := tree.root; n.aug != nil || n.left.aug != nil {
+		t.Fatalf("without gaps: expected nodes without augmentation")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("without gaps: expected MaxGap to panic")
+		}
+	}()
+	tree.MaxGap()
+}
@@ -0,0 +1,36 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// A Bucket is a range of consecutive elements of a tree.
+type Bucket struct {
+	Lo, Hi Element // smallest and largest element in the bucket
+	Count  int     // number of elements in the bucket
+}
+
+// KeyHistogram divides the elements of the tree into at most buckets
+// consecutive buckets of nearly equal size. The boundaries are found
+// through the subtree sizes kept in every node, so the cost is
+// logarithmic in the size of the tree per bucket rather than linear in
+// the number of elements.
+func (t *Tree) KeyHistogram(buckets int) []Bucket {
+	n := t.Len()
+	if n == 0 || buckets <= 0 {
+		return nil
+	}
+	if buckets > n {
+		buckets = n
+	}
+	h := make([]Bucket, buckets)
+	for i := range h {
+		lo, hi := i*n/buckets, (i+1)*n/buckets
+		h[i] = Bucket{
+			Lo:    t.root.at(lo).elem,
+			Hi:    t.root.at(hi - 1).elem,
+			Count: hi - lo,
+		}
+	}
+	return h
+}
@@ -0,0 +1,36 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyHistogram(t *testing.T) {
+	if h := (&Tree{}).KeyHistogram(4); h != nil {
+		t.Fatalf("key histogram: expected no buckets, have %v", h)
+	}
+
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 10; i++ {
+		txn.Insert(10 * i)
+	}
+	tree = txn.Commit()
+
+	want := []Bucket{
+		{compInt(0), compInt(10), 2},
+		{compInt(20), compInt(40), 3},
+		{compInt(50), compInt(60), 2},
+		{compInt(70), compInt(90), 3},
+	}
+	if h := tree.KeyHistogram(4); !reflect.DeepEqual(h, want) {
+		t.Fatalf("key histogram: expected %v, have %v", want, h)
+	}
+	if h := tree.KeyHistogram(20); len(h) != 10 || h[9] != (Bucket{compInt(90), compInt(90), 1}) {
+		t.Fatalf("key histogram: expected one bucket per element, have %v", h)
+	}
+}
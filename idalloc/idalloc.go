@@ -0,0 +1,186 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package idalloc implements an allocator handing out the lowest unused
+// integer IDs, backed by an llrb tree whose gap augmentation finds free
+// IDs in logarithmic time.
+package idalloc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mars9/llrb"
+)
+
+var (
+	// ErrInUse is returned by Reserve if an ID in the range is allocated.
+	ErrInUse = errors.New("idalloc: ID in use")
+
+	// ErrRange is returned by Reserve if the range holds more than
+	// MaxRange IDs.
+	ErrRange = errors.New("idalloc: range too large")
+)
+
+// MaxRange is the largest number of IDs AllocRange and Reserve allocate
+// at once. Allocated IDs are stored one per element, so the cost of a
+// range is linear in its size.
+const MaxRange = 1 << 16
+
+// ID is an allocated ID as stored in the tree.
+type ID int64
+
+// Compare implements the llrb.Element interface.
+func (id ID) Compare(elem llrb.Element) int {
+	o := elem.(ID)
+	switch {
+	case id < o:
+		return -1
+	case id > o:
+		return 1
+	}
+	return 0
+}
+
+// Position implements the llrb.Positioned interface.
+func (id ID) Position() int64 { return int64(id) }
+
+// Allocator hands out IDs not less than a base, lowest first. It is
+// safe for concurrent use; Snapshot can be read without blocking
+// allocations.
+type Allocator struct {
+	base int64
+
+	mu  sync.Mutex // serializes modifications
+	ids llrb.Atomic
+}
+
+// New returns an Allocator handing out IDs from base upwards.
+func New(base int64) *Allocator {
+	a := &Allocator{base: base}
+	a.ids.Store(llrb.New(llrb.WithGaps()))
+	return a
+}
+
+// Alloc allocates and returns the lowest unused ID.
+func (a *Allocator) Alloc() int64 {
+	id, _ := a.AllocRange(1)
+	return id
+}
+
+// AllocRange allocates the lowest run of n consecutive unused IDs and
+// returns the first of them. It returns false if n is less than 1 or
+// greater than MaxRange, or if no such run is left below the largest
+// int64.
+func (a *Allocator) AllocRange(n int64) (int64, bool) {
+	if n < 1 || n > MaxRange {
+		return 0, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tree := a.ids.Load()
+	lo := tree.FreeSlot(a.base, n)
+	if lo < a.base || lo+n < lo {
+		return 0, false
+	}
+	a.ids.Store(insert(tree, lo, lo+n))
+	return lo, true
+}
+
+// Reserve allocates all IDs in [lo, hi). It returns ErrInUse and
+// allocates nothing if any of them is already allocated, and ErrRange
+// if there are more than MaxRange of them. Reserved IDs may lie below
+// the base.
+func (a *Allocator) Reserve(lo, hi int64) error {
+	if lo >= hi {
+		return nil
+	}
+	if uint64(hi)-uint64(lo) > MaxRange {
+		return ErrRange
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tree := a.ids.Load()
+	if id, ok := tree.Ceil(ID(lo)).(ID); ok && int64(id) < hi {
+		return fmt.Errorf("%w: %d", ErrInUse, id)
+	}
+	a.ids.Store(insert(tree, lo, hi))
+	return nil
+}
+
+// Release frees id and reports whether it was allocated.
+func (a *Allocator) Release(id int64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tree := a.ids.Load()
+	if tree.Get(ID(id)) == nil {
+		return false
+	}
+	txn := tree.Txn()
+	txn.Delete(ID(id))
+	a.ids.Store(txn.Commit())
+	return true
+}
+
+// InUse reports whether id is allocated.
+func (a *Allocator) InUse(id int64) bool {
+	return a.ids.Load().Get(ID(id)) != nil
+}
+
+// Len returns the number of allocated IDs.
+func (a *Allocator) Len() int {
+	return a.ids.Load().Len()
+}
+
+// Snapshot returns the tree of allocated IDs, holding ID elements.
+func (a *Allocator) Snapshot() *llrb.Tree {
+	return a.ids.Load()
+}
+
+// Persist writes the allocated IDs to w in the llrb snapshot format.
+func (a *Allocator) Persist(w io.Writer) error {
+	return a.ids.Load().Persist(w, Codec{})
+}
+
+// Restore returns an Allocator handing out IDs from base upwards, with
+// the IDs read from a snapshot written by Persist allocated.
+func Restore(r io.Reader, base int64) (*Allocator, error) {
+	tree, err := llrb.Restore(r, Codec{}, llrb.WithGaps())
+	if err != nil {
+		return nil, err
+	}
+	a := New(base)
+	a.ids.Store(tree)
+	return a, nil
+}
+
+// insert returns tree with the IDs in [lo, hi), none of which is
+// allocated, inserted. IDs above the maximum are appended in a run.
+func insert(tree *llrb.Tree, lo, hi int64) *llrb.Tree {
+	txn := tree.Txn()
+	for id := lo; id < hi; id++ {
+		txn.Append(ID(id))
+	}
+	return txn.Commit()
+}
+
+// Codec is an llrb.Codec encoding IDs as varints.
+type Codec struct{}
+
+// Marshal implements the llrb.Codec interface.
+func (Codec) Marshal(elem llrb.Element) ([]byte, error) {
+	return binary.AppendVarint(nil, int64(elem.(ID))), nil
+}
+
+// Unmarshal implements the llrb.Codec interface.
+func (Codec) Unmarshal(b []byte) (llrb.Element, error) {
+	v, n := binary.Varint(b)
+	if n != len(b) {
+		return nil, errors.New("idalloc: malformed ID")
+	}
+	return ID(v), nil
+}
@@ -0,0 +1,119 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package idalloc
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	a := New(1)
+	for want := int64(1); want <= 5; want++ {
+		if id := a.Alloc(); id != want {
+			t.Fatalf("alloc: expected %d, have %d", want, id)
+		}
+	}
+	if !a.Release(2) || !a.Release(4) || a.Release(4) {
+		t.Fatalf("release: unexpected result")
+	}
+	if id := a.Alloc(); id != 2 {
+		t.Fatalf("alloc: expected released ID 2, have %d", id)
+	}
+	if id, ok := a.AllocRange(3); !ok || id != 6 {
+		t.Fatalf("alloc range: expected 6, have %d, %v", id, ok)
+	}
+	if err := a.Reserve(10, 20); err != nil {
+		t.Fatalf("reserve: unexpected error: %v", err)
+	}
+	if err := a.Reserve(15, 25); !errors.Is(err, ErrInUse) {
+		t.Fatalf("reserve: expected ErrInUse, have %v", err)
+	}
+	if a.InUse(22) {
+		t.Fatalf("reserve: failed reservation allocated IDs")
+	}
+	if id, ok := a.AllocRange(2); !ok || id != 20 {
+		t.Fatalf("alloc range: expected 20, have %d, %v", id, ok)
+	}
+	if id := a.Alloc(); id != 4 {
+		t.Fatalf("alloc: expected 4, have %d", id)
+	}
+	if _, ok := a.AllocRange(0); ok {
+		t.Fatalf("alloc range: expected failure for empty range")
+	}
+	if n := a.Len(); n != 20 {
+		t.Fatalf("len: expected 20, have %d", n)
+	}
+	if err := a.Snapshot().Check(); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+}
+
+func TestLargeRanges(t *testing.T) {
+	a := New(0)
+	if err := a.Reserve(0, MaxRange); err != nil {
+		t.Fatalf("reserve: unexpected error: %v", err)
+	}
+	if err := a.Reserve(MaxRange, 1<<40); err != ErrRange {
+		t.Fatalf("reserve: expected ErrRange, have %v", err)
+	}
+	if err := a.Reserve(math.MinInt64, math.MaxInt64); err != ErrRange {
+		t.Fatalf("reserve: expected ErrRange, have %v", err)
+	}
+	if _, ok := a.AllocRange(MaxRange + 1); ok {
+		t.Fatalf("alloc range: expected failure for oversized range")
+	}
+	if id, ok := a.AllocRange(MaxRange); !ok || id != MaxRange {
+		t.Fatalf("alloc range: expected %d, have %d, %v", MaxRange, id, ok)
+	}
+	if n := a.Len(); n != 2*MaxRange {
+		t.Fatalf("len: expected %d, have %d", 2*MaxRange, n)
+	}
+	if err := a.Snapshot().Check(); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	b := New(math.MaxInt64 - 2)
+	if _, ok := b.AllocRange(3); ok {
+		t.Fatalf("alloc range: expected failure for range overflowing int64")
+	}
+	if id, ok := b.AllocRange(2); !ok || id != math.MaxInt64-2 {
+		t.Fatalf("alloc range: expected %d, have %d, %v", int64(math.MaxInt64-2), id, ok)
+	}
+	if _, ok := b.AllocRange(1); ok {
+		t.Fatalf("alloc range: expected failure with IDs exhausted")
+	}
+}
+
+func TestPersist(t *testing.T) {
+	a := New(0)
+	if err := a.Reserve(-3, 0); err != nil {
+		t.Fatalf("reserve: unexpected error: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		a.Alloc()
+	}
+	a.Release(50)
+
+	var buf bytes.Buffer
+	if err := a.Persist(&buf); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	b, err := Restore(&buf, 0)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if b.Len() != a.Len() || !b.InUse(-3) || b.InUse(50) {
+		t.Fatalf("restore: allocated IDs differ")
+	}
+	if id := b.Alloc(); id != 50 {
+		t.Fatalf("restore: expected 50, have %d", id)
+	}
+	if id := b.Alloc(); id != 100 {
+		t.Fatalf("restore: expected 100, have %d", id)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvariant is wrapped by the errors returned by Check.
+var ErrInvariant = errors.New("llrb: invariant violated")
+
+// Check verifies the structure of the tree: elements in ascending
+// order, a black root, no right-leaning or consecutive red links, equal
+// black height on all paths, and consistent subtree sizes, weights,
+// byte sizes, digests and gaps. It returns an error wrapping
+// ErrInvariant that describes the first violation found, or nil. Check
+// visits every node and is meant for tests and for validating restored
+// or imported trees.
+func (t *Tree) Check() error {
+	if t == nil || t.root == nil {
+		if t.Len() != 0 {
+			return fmt.Errorf("%w: empty tree has length %d", ErrInvariant, t.Len())
+		}
+		return nil
+	}
+	if t.root.isRed() {
+		return fmt.Errorf("%w: red root", ErrInvariant)
+	}
+	if _, err := t.root.check(nil, nil); err != nil {
+		return err
+	}
+	if n := t.root.len(); n != t.size {
+		return fmt.Errorf("%w: tree has length %d but holds %d elements", ErrInvariant, t.size, n)
+	}
+	return nil
+}
+
+// check verifies the subtree rooted at n, whose elements must lie
+// within (lo, hi), and returns its black height.
+func (n *node) check(lo, hi Element) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+	if (lo != nil && n.elem.Compare(lo) <= 0) || (hi != nil && n.elem.Compare(hi) >= 0) {
+		return 0, fmt.Errorf("%w: %v out of order", ErrInvariant, n.elem)
+	}
+	if n.right.isRed() {
+		return 0, fmt.Errorf("%w: right-leaning red link below %v", ErrInvariant, n.elem)
+	}
+	if n.isRed() && n.left.isRed() {
+		return 0, fmt.Errorf("%w: consecutive red links below %v", ErrInvariant, n.elem)
+	}
+	lh, err := n.left.check(lo, n.elem)
+	if err != nil {
+		return 0, err
+	}
+	rh, err := n.right.check(n.elem, hi)
+	if err != nil {
+		return 0, err
+	}
+	if lh != rh {
+		return 0, fmt.Errorf("%w: black heights %d and %d below %v", ErrInvariant, lh, rh, n.elem)
+	}
+	m := n.copy()
+	m.update()
+	switch {
+	case m.size != n.size:
+		return 0, fmt.Errorf("%w: subtree size %d at %v, want %d", ErrInvariant, n.size, n.elem, m.size)
+	case m.aug != nil && m.aug.weight != n.aug.weight:
+		return 0, fmt.Errorf("%w: subtree weight %v at %v, want %v", ErrInvariant, n.aug.weight, n.elem, m.aug.weight)
+	case m.aug != nil && m.aug.bytes != n.aug.bytes:
+		return 0, fmt.Errorf("%w: subtree bytes %d at %v, want %d", ErrInvariant, n.aug.bytes, n.elem, m.aug.bytes)
+	case m.aug != nil && m.aug.digest != n.aug.digest:
+		return 0, fmt.Errorf("%w: stale digest at %v", ErrInvariant, n.elem)
+	case m.aug != nil && (m.aug.lo != n.aug.lo || m.aug.hi != n.aug.hi || m.aug.minGap != n.aug.minGap || m.aug.maxGap != n.aug.maxGap):
+		return 0, fmt.Errorf("%w: stale gaps at %v", ErrInvariant, n.elem)
+	}
+	if !n.isRed() {
+		lh++
+	}
+	return lh, nil
+}
+
+// WithStrictChecks makes every transaction Check the tree after each
+// operation that changed it and panic with an error wrapping
+// ErrInvariant, followed by a dump of the tree structure, on the first
+// violation. Each check visits the whole tree, so the option is meant
+// for integration tests and debugging.
+func WithStrictChecks() Option {
+	return func(t *Tree) {
+		t.strict = true
+	}
+}
+
+// dumpMax is the number of nodes shown by the dump of a strict check.
+const dumpMax = 512
+
+// verify checks the tree of t if it changed since the last check.
+func (t *Txn) verify() {
+	if t.tree.root == t.verified {
+		return
+	}
+	if err := t.tree.Check(); err != nil {
+		panic(fmt.Errorf("%w\n%s", err, t.tree.dump(dumpMax)))
+	}
+	t.verified = t.tree.root
+}
+
+// dump returns the structure of the tree sideways, one node per line
+// with right subtrees above and left subtrees below their parent,
+// indented by depth, with color and subtree size. Nodes beyond the
+// first max are left out.
+func (t *Tree) dump(max int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tree of length %d:\n", t.Len())
+	n := 0
+	var walk func(x *node, depth int)
+	walk = func(x *node, depth int) {
+		if x == nil || n > max {
+			return
+		}
+		walk(x.right, depth+1)
+		if n++; n > max {
+			b.WriteString("...\n")
+			return
+		}
+		c := Red
+		if x.color == black {
+			c = Black
+		}
+		fmt.Fprintf(&b, "%s%v (%v, %d)\n", strings.Repeat("  ", depth), x.elem, c, x.size)
+		walk(x.left, depth+1)
+	}
+	if t != nil {
+		walk(t.root, 0)
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	if err := (*Tree)(nil).Check(); err != nil {
+		t.Fatalf("check: unexpected error for nil tree: %v", err)
+	}
+	tree := randomTree(1000, 5000)
+	if err := tree.Check(); err != nil {
+		t.Fatalf("check: unexpected error: %v", err)
+	}
+
+	for name, corrupt := range map[string]func(*Tree){
+		"order":  func(t *Tree) { t.root.left.elem, t.root.right.elem = t.root.right.elem, t.root.left.elem },
+		"root":   func(t *Tree) { t.root.color = red },
+		"size":   func(t *Tree) { t.size++ },
+		"lean":   func(t *Tree) { t.root.right.color = red },
+		"height": func(t *Tree) { t.root.left = t.root.left.left },
+	} {
+		c := tree.Compact()
+		corrupt(c)
+		if err := c.Check(); !errors.Is(err, ErrInvariant) {
+			t.Fatalf("check %s: expected ErrInvariant, have %v", name, err)
+		}
+	}
+}
+
+func TestStrictChecks(t *testing.T) {
+	tree := New(WithStrictChecks())
+	txn := tree.Txn()
+	for i := 0; i < 200; i++ {
+		txn.Insert(compInt(i * 7 % 200))
+	}
+	for i := 0; i < 100; i++ {
+		txn.Delete(compInt(i * 3 % 200))
+	}
+	txn.DeleteMin()
+	txn.DeleteMax()
+	tree = txn.Commit()
+
+	txn = tree.Txn()
+	txn.Insert(compInt(1000))
+	txn.tree.size++
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrInvariant) || !strings.Contains(err.Error(), "(black, ") {
+			t.Fatalf("strict checks: expected invariant panic with dump, got %v", err)
+		}
+	}()
+	txn.Insert(compInt(1001))
+	t.Fatalf("strict checks: expected panic")
+}
@@ -0,0 +1,90 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/bits"
+	"net/netip"
+)
+
+// IP is an Element holding an IP address and an associated value. IPs
+// are ordered as by netip.Addr.Compare: IPv4 addresses before IPv6
+// addresses, each in numerical order. The value does not take part in
+// the comparison.
+type IP struct {
+	Addr  netip.Addr
+	Value interface{}
+}
+
+// Compare implements the Element interface. It panics if elem is not
+// an IP.
+func (ip IP) Compare(elem Element) int {
+	return ip.Addr.Compare(elem.(IP).Addr)
+}
+
+// CIDR is an Element holding a block of IP addresses and an associated
+// value. The prefix must be in canonical form, as returned by
+// netip.Prefix.Masked. Blocks are ordered by their first address and
+// blocks starting at the same address by prefix length, so a block
+// sorts before all blocks it contains. The value does not take part in
+// the comparison.
+type CIDR struct {
+	Prefix netip.Prefix
+	Value  interface{}
+}
+
+// Compare implements the Element interface. It panics if elem is not a
+// CIDR.
+func (c CIDR) Compare(elem Element) int {
+	p := elem.(CIDR).Prefix
+	if cmp := c.Prefix.Addr().Compare(p.Addr()); cmp != 0 {
+		return cmp
+	}
+	return c.Prefix.Bits() - p.Bits()
+}
+
+// LookupIP returns the most specific block in t containing addr. The
+// tree must hold CIDR elements only, which may overlap.
+//
+// The search starts with the largest block sorting before addr. If that
+// block does not contain addr, any block that does must also contain
+// the first address of the block found, so the search continues below
+// the prefix addr has in common with it.
+func LookupIP(t *Tree, addr netip.Addr) (CIDR, bool) {
+	if !addr.IsValid() {
+		return CIDR{}, false
+	}
+	key := CIDR{Prefix: netip.PrefixFrom(addr, addr.BitLen())}
+	for {
+		elem := t.Floor(key)
+		if elem == nil {
+			return CIDR{}, false
+		}
+		c := elem.(CIDR)
+		if c.Prefix.Contains(addr) {
+			return c, true
+		}
+		if c.Prefix.Addr().BitLen() != addr.BitLen() {
+			return CIDR{}, false
+		}
+		p, _ := addr.Prefix(commonBits(c.Prefix.Addr(), addr))
+		key = CIDR{Prefix: p}
+	}
+}
+
+// commonBits returns the length of the common prefix of two addresses
+// of the same family.
+func commonBits(a, b netip.Addr) int {
+	x, y := a.As16(), b.As16()
+	n := 0
+	for i := range x {
+		if d := x[i] ^ y[i]; d != 0 {
+			n += bits.LeadingZeros8(d)
+			break
+		}
+		n += 8
+	}
+	return n - (128 - a.BitLen())
+}
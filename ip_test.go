@@ -0,0 +1,84 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIP(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for _, s := range []string{"10.0.0.2", "::1", "10.0.0.10", "1.2.3.4"} {
+		txn.Insert(IP{Addr: netip.MustParseAddr(s)})
+	}
+	tree = txn.Commit()
+
+	var have []string
+	tree.ForEach(func(elem Element) bool {
+		have = append(have, elem.(IP).Addr.String())
+		return false
+	})
+	want := []string{"1.2.3.4", "10.0.0.2", "10.0.0.10", "::1"}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Fatalf("ip: expected order %v, have %v", want, have)
+		}
+	}
+}
+
+func TestLookupIP(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for _, s := range []string{
+		"10.0.0.0/8",
+		"10.1.0.0/16",
+		"10.1.2.0/24",
+		"10.1.2.128/25",
+		"10.2.0.0/16",
+		"192.168.0.0/16",
+		"2001:db8::/32",
+		"2001:db8:1::/48",
+	} {
+		p := netip.MustParsePrefix(s)
+		txn.Insert(CIDR{Prefix: p, Value: s})
+	}
+	tree = txn.Commit()
+
+	for _, test := range []struct {
+		addr, want string
+	}{
+		{"10.0.0.1", "10.0.0.0/8"},
+		{"10.1.0.1", "10.1.0.0/16"},
+		{"10.1.2.3", "10.1.2.0/24"},
+		{"10.1.2.200", "10.1.2.128/25"},
+		{"10.1.3.1", "10.1.0.0/16"},
+		{"10.2.255.255", "10.2.0.0/16"},
+		{"10.3.0.0", "10.0.0.0/8"},
+		{"10.255.255.255", "10.0.0.0/8"},
+		{"11.0.0.0", ""},
+		{"9.255.255.255", ""},
+		{"192.168.1.1", "192.168.0.0/16"},
+		{"2001:db8:1::1", "2001:db8:1::/48"},
+		{"2001:db8:2::1", "2001:db8::/32"},
+		{"2001:db9::1", ""},
+		{"::1", ""},
+	} {
+		c, ok := LookupIP(tree, netip.MustParseAddr(test.addr))
+		if test.want == "" {
+			if ok {
+				t.Fatalf("lookup ip: unexpected block %v for %s", c.Prefix, test.addr)
+			}
+			continue
+		}
+		if !ok || c.Value != test.want {
+			t.Fatalf("lookup ip: expected block %s for %s, got %v (%t)", test.want, test.addr, c.Value, ok)
+		}
+	}
+	if _, ok := LookupIP(tree, netip.Addr{}); ok {
+		t.Fatalf("lookup ip: unexpected block for invalid address")
+	}
+}
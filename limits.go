@@ -0,0 +1,59 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "errors"
+
+// ErrTxnLimit is returned by TryInsert and TryDelete once a transaction
+// has exceeded one of its TxnLimits. Insert and the Delete methods
+// panic with it.
+var ErrTxnLimit = errors.New("llrb: transaction limit exceeded")
+
+// TxnLimits bounds the work done by a transaction. A zero field means
+// no limit. Node counts are estimates derived from the number of nodes
+// visited, each of which is copied by a modification.
+type TxnLimits struct {
+	Ops   int // inserts and deletes
+	Nodes int // nodes copied
+	Bytes int // bytes allocated for copied nodes
+}
+
+// SetLimits sets the limits of the transaction. Once a limit has been
+// reached, further inserts and deletes fail with ErrTxnLimit, while the
+// modifications made so far remain and can still be committed.
+func (t *Txn) SetLimits(l TxnLimits) {
+	t.enter()
+	defer t.leave()
+	t.limits = l
+}
+
+// admit returns ErrTxnLimit if t may not perform further modifications.
+func (t *Txn) admit() error {
+	l := t.limits
+	if (l.Ops > 0 && t.ops >= l.Ops) ||
+		(l.Nodes > 0 && t.copied >= l.Nodes) ||
+		(l.Bytes > 0 && t.copied*t.tree.nodeSize() >= l.Bytes) {
+		return ErrTxnLimit
+	}
+	return nil
+}
+
+// probe returns a probe for a modification, or nil if neither
+// statistics, limits nor consolidation need one.
+func (t *Txn) probe() *probe {
+	if t.tree.stats == nil && t.limits == (TxnLimits{}) && t.tree.consolidateRatio == 0 {
+		return nil
+	}
+	return &probe{}
+}
+
+// account records a completed modification.
+func (t *Txn) account(op int, p *probe) {
+	t.ops++
+	if p != nil {
+		t.copied += p.depth
+	}
+	t.tree.stats.record(op, p)
+}
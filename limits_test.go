@@ -0,0 +1,53 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "testing"
+
+func TestTxnLimits(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	txn.SetLimits(TxnLimits{Ops: 10})
+	for i := compInt(0); i < 10; i++ {
+		if err := txn.TryInsert(i); err != nil {
+			t.Fatalf("txn limits: unexpected error %v", err)
+		}
+	}
+	if err := txn.TryInsert(compInt(10)); err != ErrTxnLimit {
+		t.Fatalf("txn limits: expected %v, got %v", ErrTxnLimit, err)
+	}
+	if err := txn.TryDelete(compInt(0)); err != ErrTxnLimit {
+		t.Fatalf("txn limits: expected %v, got %v", ErrTxnLimit, err)
+	}
+	func() {
+		defer func() {
+			if v := recover(); v != ErrTxnLimit {
+				t.Fatalf("txn limits: expected panic with %v, got %v", ErrTxnLimit, v)
+			}
+		}()
+		txn.DeleteMin()
+	}()
+	if tree = txn.Commit(); tree.Len() != 10 {
+		t.Fatalf("txn limits: expected tree length 10, have %d", tree.Len())
+	}
+
+	txn = tree.Txn()
+	txn.SetLimits(TxnLimits{Bytes: 20 * nodeBytes})
+	var n int
+	for i := compInt(10); txn.TryInsert(i) == nil; i++ {
+		n++
+	}
+	if n == 0 || txn.copied < 20 || txn.copied >= 40 {
+		t.Fatalf("txn limits: unexpected %d inserts copying %d nodes", n, txn.copied)
+	}
+
+	txn = New(WithWeights()).Txn()
+	txn.SetLimits(TxnLimits{Bytes: 20 * augNodeBytes})
+	for n = 0; txn.TryInsert(compInt(n)) == nil; n++ {
+	}
+	if n == 0 || txn.copied < 20 || txn.copied >= 40 {
+		t.Fatalf("txn limits: unexpected %d augmented inserts copying %d nodes", n, txn.copied)
+	}
+}
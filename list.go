@@ -0,0 +1,169 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// List is an immutable sequence of values. It is kept in the same
+// balanced tree as Tree, but ordered by position instead of by Compare:
+// the subtree sizes kept in every node locate an index in logarithmic
+// time. Insert, Delete and Set return a new List sharing all untouched
+// nodes with the receiver. A nil *List is an empty list.
+type List struct {
+	root *node
+}
+
+// listItem wraps a value stored in a List. Lists never compare their
+// values.
+type listItem struct {
+	v interface{}
+}
+
+func (listItem) Compare(Element) int { panic("list values are not ordered") }
+
+// Len returns the number of values in the list.
+func (l *List) Len() int {
+	if l == nil {
+		return 0
+	}
+	return l.root.len()
+}
+
+// At returns the value at index i. At panics if i is out of range.
+func (l *List) At(i int) interface{} {
+	if i < 0 || i >= l.Len() {
+		panic("index out of range")
+	}
+	return l.root.at(i).elem.(listItem).v
+}
+
+// Insert returns the list with v inserted at index i, shifting the
+// values from i on up by one. Insert panics if i is not within
+// [0, l.Len()].
+func (l *List) Insert(i int, v interface{}) *List {
+	if i < 0 || i > l.Len() {
+		panic("index out of range")
+	}
+	var root *node
+	if l != nil {
+		root = l.root
+	}
+	root = root.insertAt(i, listItem{v})
+	root.color = black
+	return &List{root: root}
+}
+
+// Append returns the list with v added at the end.
+func (l *List) Append(v interface{}) *List {
+	return l.Insert(l.Len(), v)
+}
+
+// Delete returns the list with the value at index i removed, shifting
+// the values after i down by one. Delete panics if i is out of range.
+func (l *List) Delete(i int) *List {
+	if i < 0 || i >= l.Len() {
+		panic("index out of range")
+	}
+	root := l.root.deleteAt(i)
+	if root != nil {
+		root.color = black
+	}
+	return &List{root: root}
+}
+
+// Set returns the list with the value at index i replaced by v. Set
+// panics if i is out of range.
+func (l *List) Set(i int, v interface{}) *List {
+	if i < 0 || i >= l.Len() {
+		panic("index out of range")
+	}
+	return &List{root: l.root.setAt(i, listItem{v})}
+}
+
+// ForEach performs fn on all values in sequence order. A boolean is
+// returned indicating whether the traversal was interrupted by fn
+// returning true.
+func (l *List) ForEach(fn func(v interface{}) (done bool)) bool {
+	if l.Len() == 0 {
+		return false
+	}
+	return l.root.do(func(elem Element) bool {
+		return fn(elem.(listItem).v)
+	})
+}
+
+// insertAt inserts elem so that it has rank i in the subtree rooted at
+// n. It mirrors insert with the path chosen by position.
+func (n *node) insertAt(i int, elem Element) *node {
+	if n == nil {
+		return newNode(elem, 0)
+	}
+
+	root := n.copy() // recursive branch copy
+	if l := root.left.len(); i <= l {
+		root.left = root.left.insertAt(i, elem)
+	} else {
+		root.right = root.right.insertAt(i-l-1, elem)
+	}
+	root.update()
+
+	if root.right.isRed() && !root.left.isRed() {
+		root = root.rotateLeft()
+	}
+	if root.left.isRed() && root.left.left.isRed() {
+		root = root.rotateRight()
+	}
+	if root.left.isRed() && root.right.isRed() {
+		root.flipColors()
+	}
+	return root
+}
+
+// deleteAt deletes the element of rank i from the subtree rooted at n.
+// It mirrors delete with the path chosen by position; restructuring
+// never changes the rank of an element within the subtree.
+func (n *node) deleteAt(i int) *node {
+	root := n.copy() // recursive branch copy
+
+	if i < root.left.len() {
+		if !root.left.isRed() && !root.left.left.isRed() {
+			root = root.moveRedLeft()
+		}
+		root.left = root.left.deleteAt(i)
+	} else {
+		if root.left.isRed() {
+			root = root.rotateRight()
+		}
+		if root.right == nil {
+			return nil
+		}
+		if !root.right.isRed() && !root.right.left.isRed() {
+			root = root.moveRedRight()
+		}
+		if l := root.left.len(); i == l {
+			root.elem = root.right.min().elem
+			root.right, _ = root.right.deleteMin(nil)
+		} else {
+			root.right = root.right.deleteAt(i - l - 1)
+		}
+	}
+	root.update()
+
+	return root.fixUp()
+}
+
+// setAt replaces the element of rank i in the subtree rooted at n,
+// copying the path to it.
+func (n *node) setAt(i int, elem Element) *node {
+	root := n.copy()
+	switch l := root.left.len(); {
+	case i < l:
+		root.left = root.left.setAt(i, elem)
+	case i == l:
+		root.elem = elem
+	default:
+		root.right = root.right.setAt(i-l-1, elem)
+	}
+	root.update()
+	return root
+}
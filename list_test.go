@@ -0,0 +1,61 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func listValues(l *List) []interface{} {
+	vs := []interface{}{}
+	l.ForEach(func(v interface{}) bool {
+		vs = append(vs, v)
+		return false
+	})
+	return vs
+}
+
+func TestList(t *testing.T) {
+	var l *List
+	want := []interface{}{}
+	for i := 0; i < 2000; i++ {
+		prev, prevWant := l, append([]interface{}{}, want...)
+		switch n := l.Len(); {
+		case n > 0 && rand.Intn(3) == 0:
+			j := rand.Intn(n)
+			l = l.Delete(j)
+			want = append(want[:j], want[j+1:]...)
+		case n > 0 && rand.Intn(5) == 0:
+			j := rand.Intn(n)
+			l = l.Set(j, -i)
+			want[j] = -i
+		default:
+			j := rand.Intn(n + 1)
+			l = l.Insert(j, i)
+			want = append(want[:j], append([]interface{}{i}, want[j:]...)...)
+		}
+
+		if l.Len() != len(want) {
+			t.Fatalf("list: expected length %d, have %d", len(want), l.Len())
+		}
+		if tree := (&Tree{root: l.root, size: l.Len()}); l.root != nil &&
+			(!tree.is23() || !tree.isBalanced() || !tree.isSized()) {
+			t.Fatalf("list: invariants violated after %d operations", i+1)
+		}
+		if vs := listValues(l); !reflect.DeepEqual(vs, want) {
+			t.Fatalf("list: expected %v, have %v", want, vs)
+		}
+		if vs := listValues(prev); !reflect.DeepEqual(vs, prevWant) {
+			t.Fatalf("list: previous version modified")
+		}
+	}
+	for i, v := range want {
+		if l.At(i) != v {
+			t.Fatalf("list: expected %v at %d, have %v", v, i, l.At(i))
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "unsafe"
+
+// nodeBytes and augNodeBytes are the sizes in bytes of a single plain
+// and augmented tree node, not counting the memory referenced by its
+// Element.
+const (
+	nodeBytes    = int(unsafe.Sizeof(node{}))
+	augNodeBytes = int(unsafe.Sizeof(augNode{}))
+)
+
+// footprint returns the size in bytes of n.
+func (n *node) footprint() int {
+	if n.aug != nil {
+		return augNodeBytes
+	}
+	return nodeBytes
+}
+
+// nodeSize returns the size in bytes of a node of t.
+func (t *Tree) nodeSize() int {
+	if t.kinds() != 0 {
+		return augNodeBytes
+	}
+	return nodeBytes
+}
+
+// LiveNodes returns the number of distinct nodes reachable from the
+// given trees. Nodes shared between versions through branch copying are
+// counted once, so the result reflects the memory actually retained by
+// holding on to all roots.
+func LiveNodes(roots ...*Tree) int {
+	return len(live(roots))
+}
+
+// LiveBytes returns the number of bytes occupied by the distinct nodes
+// reachable from the given trees. Memory referenced by stored elements
+// is not included.
+func LiveBytes(roots ...*Tree) int {
+	var b int
+	for n := range live(roots) {
+		b += n.footprint()
+	}
+	return b
+}
+
+// live returns the set of distinct nodes reachable from roots.
+func live(roots []*Tree) map[*node]struct{} {
+	seen := make(map[*node]struct{})
+	for _, t := range roots {
+		if t == nil {
+			continue
+		}
+		t.root.walkUnique(seen)
+	}
+	return seen
+}
+
+// Garbage returns the number of nodes of prev that are not reachable
+// from any of the retained trees. Called after committing a new version
+// with prev being the version it replaced, it estimates the nodes the
+// garbage collector can reclaim once prev is dropped.
+func Garbage(prev *Tree, retained ...*Tree) int {
+	if prev == nil {
+		return 0
+	}
+	seen := make(map[*node]struct{})
+	for _, t := range retained {
+		if t == nil {
+			continue
+		}
+		t.root.walkUnique(seen)
+	}
+	var n int
+	var count func(*node)
+	count = func(x *node) {
+		for ; x != nil; x = x.right {
+			if _, ok := seen[x]; ok {
+				// Nodes below a shared node are shared too.
+				return
+			}
+			n++
+			count(x.left)
+		}
+	}
+	count(prev.root)
+	return n
+}
+
+func (n *node) walkUnique(seen map[*node]struct{}) {
+	for n != nil {
+		if _, ok := seen[n]; ok {
+			return
+		}
+		seen[n] = struct{}{}
+		n.left.walkUnique(seen)
+		n = n.right
+	}
+}
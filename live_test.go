@@ -0,0 +1,120 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func elements(t *Tree) []Element {
+	var elems []Element
+	t.ForEach(func(elem Element) bool {
+		elems = append(elems, elem)
+		return false
+	})
+	return elems
+}
+
+func TestLiveNodes(t *testing.T) {
+	if n := LiveNodes(); n != 0 {
+		t.Fatalf("live nodes: expected 0 nodes, have %d", n)
+	}
+	if n := LiveNodes(nil, &Tree{}); n != 0 {
+		t.Fatalf("live nodes: expected 0 nodes, have %d", n)
+	}
+
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 1000; i++ {
+		txn.Insert(i)
+	}
+	v1 := txn.Commit()
+	if n := LiveNodes(v1); n != v1.Len() {
+		t.Fatalf("live nodes: expected %d nodes, have %d", v1.Len(), n)
+	}
+	if n := LiveNodes(v1, v1); n != v1.Len() {
+		t.Fatalf("live nodes: expected %d nodes, have %d", v1.Len(), n)
+	}
+
+	txn = v1.Txn()
+	txn.Insert(compInt(1000))
+	v2 := txn.Commit()
+	n := LiveNodes(v1, v2)
+	if n <= v2.Len() || n >= v1.Len()+v2.Len() {
+		t.Fatalf("live nodes: expected shared nodes between versions, have %d", n)
+	}
+	if b := LiveBytes(v1, v2); b != n*nodeBytes {
+		t.Fatalf("live bytes: expected %d bytes, have %d", n*nodeBytes, b)
+	}
+
+	aug := New(WithWeights())
+	txn = aug.Txn()
+	for i := compInt(0); i < 1000; i++ {
+		txn.Insert(i)
+	}
+	a1 := txn.Commit()
+	if n := LiveNodes(a1); n != LiveNodes(v1) {
+		t.Fatalf("live nodes: expected %d nodes, have %d", LiveNodes(v1), n)
+	}
+	if b, p := LiveBytes(a1), LiveBytes(v1); b <= p || b != LiveNodes(a1)*augNodeBytes {
+		t.Fatalf("live bytes: expected %d bytes for augmented nodes, have %d (plain %d)",
+			LiveNodes(a1)*augNodeBytes, b, p)
+	}
+}
+
+func TestGarbage(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 1000; i++ {
+		txn.Insert(i)
+	}
+	v1 := txn.Commit()
+	if n := Garbage(v1); n != v1.Len() {
+		t.Fatalf("garbage: expected %d nodes, have %d", v1.Len(), n)
+	}
+	if n := Garbage(v1, v1); n != 0 {
+		t.Fatalf("garbage: expected 0 nodes, have %d", n)
+	}
+
+	txn = v1.Txn()
+	txn.Delete(compInt(500))
+	v2 := txn.Commit()
+	want := LiveNodes(v1, v2) - LiveNodes(v2)
+	if n := Garbage(v1, v2); n != want || n == 0 {
+		t.Fatalf("garbage: expected %d nodes, have %d", want, n)
+	}
+	if n := Garbage(nil, v2); n != 0 {
+		t.Fatalf("garbage: expected 0 nodes, have %d", n)
+	}
+}
+
+func TestRetainedVersionsUnchanged(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 100; i++ {
+		txn.Insert(i)
+	}
+	v1 := txn.Commit()
+	want := elements(v1)
+
+	txn = v1.Txn()
+	for i := compInt(0); i < 100; i += 2 {
+		txn.Delete(i)
+	}
+	txn.DeleteMin()
+	txn.DeleteMax()
+	for i := compInt(100); i < 200; i++ {
+		txn.Insert(i)
+	}
+	txn.Commit()
+
+	if !reflect.DeepEqual(want, elements(v1)) {
+		t.Fatalf("retained version: elements changed by later transaction")
+	}
+	if !v1.isBST() || !v1.isBalanced() || !v1.is23() {
+		t.Fatalf("retained version: invariants violated by later transaction")
+	}
+}
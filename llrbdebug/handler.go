@@ -0,0 +1,229 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package llrbdebug serves debugging information about llrb trees over
+// HTTP, in the spirit of expvar and net/http/pprof. Mount a Handler
+// under a prefix with http.StripPrefix:
+//
+//	h := llrbdebug.New(requireAdmin)
+//	h.Register("users", users, history)
+//	mux.Handle("/debug/llrb/", http.StripPrefix("/debug/llrb", h))
+//
+// It serves these endpoints, all with JSON responses:
+//
+//	/                      registered trees and their lengths
+//	/{name}/stats          length and operation statistics
+//	/{name}/versions       lengths of retained versions
+//	/{name}/elements       elements formatted with %v, bounded by ?offset=&limit=
+//
+// With ?fingerprint=1 the stats and versions endpoints also report the
+// fingerprint of each version. Computing one visits every element, so
+// on large trees such a request can take seconds.
+//
+// The endpoints expose the stored elements. Every request must pass the
+// auth function given to New, and a Handler created without one
+// rejects all requests.
+package llrbdebug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mars9/llrb"
+)
+
+// DefaultLimit is the number of elements served by the elements
+// endpoint if the request sets no limit.
+const DefaultLimit = 100
+
+// Handler is an http.Handler serving debugging information about the
+// registered trees. It is safe for concurrent use.
+type Handler struct {
+	// MaxElements bounds the limit of element dumps. Zero means 1000.
+	MaxElements int
+
+	auth http.Handler
+
+	mu    sync.RWMutex
+	trees map[string]registered
+}
+
+type registered struct {
+	root    *llrb.Atomic
+	history *llrb.Retention // nil if no versions are retained
+}
+
+// New returns a Handler with no registered trees. All requests pass
+// through auth, which typically checks credentials and calls the
+// handler it wraps only for authorized requests. A nil auth rejects all
+// requests with 403 Forbidden; to serve them without checks, pass a
+// function returning the handler it is given.
+func New(auth func(http.Handler) http.Handler) *Handler {
+	h := &Handler{trees: map[string]registered{}}
+	if auth == nil {
+		h.auth = http.HandlerFunc(forbidden)
+	} else {
+		h.auth = auth(http.HandlerFunc(h.route))
+	}
+	return h
+}
+
+func forbidden(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "forbidden", http.StatusForbidden)
+}
+
+// Register makes the tree held by root available under name, replacing
+// a tree registered under the same name. history may be nil.
+func (h *Handler) Register(name string, root *llrb.Atomic, history *llrb.Retention) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.trees[name] = registered{root: root, history: history}
+}
+
+// Unregister removes the tree registered under name.
+func (h *Handler) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.trees, name)
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.auth.ServeHTTP(w, r)
+}
+
+// route dispatches authorized requests to the endpoints.
+func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "" {
+		h.index(w, r)
+		return
+	}
+	name, endpoint, _ := strings.Cut(path, "/")
+	h.mu.RLock()
+	t, ok := h.trees[name]
+	h.mu.RUnlock()
+	if !ok {
+		http.Error(w, "unknown tree", http.StatusNotFound)
+		return
+	}
+	fingerprint := r.URL.Query().Get("fingerprint") == "1"
+	switch endpoint {
+	case "stats":
+		h.stats(w, t, fingerprint)
+	case "versions":
+		h.versions(w, t, fingerprint)
+	case "elements":
+		h.elements(w, r, t)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Tree describes a registered tree.
+type Tree struct {
+	Name string `json:"name"`
+	Len  int    `json:"len"`
+}
+
+func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	trees := make([]Tree, 0, len(h.trees))
+	for name, t := range h.trees {
+		trees = append(trees, Tree{Name: name, Len: t.root.Load().Len()})
+	}
+	h.mu.RUnlock()
+	sort.Slice(trees, func(i, j int) bool { return trees[i].Name < trees[j].Name })
+	writeJSON(w, trees)
+}
+
+// Version describes one version of a tree. The fingerprint is only
+// set if it was requested.
+type Version struct {
+	Len         int    `json:"len"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+func describe(t *llrb.Tree, fingerprint bool) Version {
+	v := Version{Len: t.Len()}
+	if fingerprint {
+		v.Fingerprint = fmt.Sprintf("%016x", t.Fingerprint())
+	}
+	return v
+}
+
+// Stats describes the current version of a tree.
+type Stats struct {
+	Version
+	Ops llrb.Stats `json:"ops"`
+}
+
+func (h *Handler) stats(w http.ResponseWriter, t registered, fingerprint bool) {
+	tree := t.root.Load()
+	writeJSON(w, Stats{Version: describe(tree, fingerprint), Ops: tree.Stats()})
+}
+
+func (h *Handler) versions(w http.ResponseWriter, t registered, fingerprint bool) {
+	versions := []Version{}
+	if t.history != nil {
+		for _, v := range t.history.Versions() {
+			versions = append(versions, describe(v, fingerprint))
+		}
+	}
+	writeJSON(w, versions)
+}
+
+// Elements is a page of elements of a tree.
+type Elements struct {
+	Len      int      `json:"len"`
+	Offset   int      `json:"offset"`
+	Elements []string `json:"elements"`
+}
+
+func (h *Handler) elements(w http.ResponseWriter, r *http.Request, t registered) {
+	max := h.MaxElements
+	if max <= 0 {
+		max = 1000
+	}
+	offset, err1 := intParam(r, "offset", 0)
+	limit, err2 := intParam(r, "limit", DefaultLimit)
+	if err1 != nil || err2 != nil || offset < 0 || limit < 0 {
+		http.Error(w, "invalid offset or limit", http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, max)
+
+	tree := t.root.Load()
+	page := Elements{Len: tree.Len(), Offset: offset, Elements: []string{}}
+	for i := offset; i < offset+limit && i < tree.Len(); i++ {
+		page.Elements = append(page.Elements, fmt.Sprint(tree.At(i)))
+	}
+	writeJSON(w, page)
+}
+
+// intParam returns the integer query parameter key of r, or def if it is
+// not set.
+func intParam(r *http.Request, key string, def int) (int, error) {
+	s := r.URL.Query().Get(key)
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,115 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrbdebug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+type key int
+
+func (k key) Compare(elem llrb.Element) int { return int(k) - int(elem.(key)) }
+
+func get(t *testing.T, h http.Handler, path string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	if rec.Code == http.StatusOK && v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	root := llrb.NewAtomic(llrb.New(llrb.WithStats()))
+	history := llrb.NewRetention(10, 0)
+	for i := 0; i < 3; i++ {
+		txn := root.Load().Txn()
+		for j := 0; j < 10; j++ {
+			txn.Insert(key(10*i + j))
+		}
+		history.Store(root, txn.Commit())
+	}
+	root.Load().Get(key(1))
+
+	h := New(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Denied") != "" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	h.MaxElements = 5
+	h.Register("keys", root, history)
+	h.Register("plain", llrb.NewAtomic(nil), nil)
+
+	var trees []Tree
+	if code := get(t, h, "/", &trees); code != http.StatusOK {
+		t.Fatalf("index: unexpected status %d", code)
+	}
+	if want := []Tree{{"keys", 30}, {"plain", 0}}; !reflect.DeepEqual(trees, want) {
+		t.Fatalf("index: expected %v, have %v", want, trees)
+	}
+
+	var stats Stats
+	get(t, h, "/keys/stats", &stats)
+	if stats.Len != 30 || stats.Ops.Get.Count != 1 || stats.Ops.Insert.Count != 30 {
+		t.Fatalf("stats: unexpected %+v", stats)
+	}
+	var versions []Version
+	get(t, h, "/keys/versions", &versions)
+	if len(versions) != 3 || versions[0].Len != 10 || versions[2] != stats.Version || stats.Fingerprint != "" {
+		t.Fatalf("versions: unexpected %+v", versions)
+	}
+	get(t, h, "/keys/stats?fingerprint=1", &stats)
+	get(t, h, "/keys/versions?fingerprint=1", &versions)
+	if stats.Fingerprint == "" || versions[2] != stats.Version || versions[0].Fingerprint == stats.Fingerprint {
+		t.Fatalf("versions: unexpected fingerprints %+v", versions)
+	}
+	get(t, h, "/plain/versions", &versions)
+	if len(versions) != 0 {
+		t.Fatalf("versions: expected none, have %+v", versions)
+	}
+
+	var page Elements
+	get(t, h, "/keys/elements?offset=8&limit=100", &page)
+	if want := []string{"8", "9", "10", "11", "12"}; page.Len != 30 || !reflect.DeepEqual(page.Elements, want) {
+		t.Fatalf("elements: expected %v, have %+v", want, page)
+	}
+	get(t, h, "/keys/elements?offset=28", &page)
+	if want := []string{"28", "29"}; !reflect.DeepEqual(page.Elements, want) {
+		t.Fatalf("elements: expected %v, have %+v", want, page)
+	}
+
+	for path, want := range map[string]int{
+		"/nope/stats":            http.StatusNotFound,
+		"/keys/other":            http.StatusNotFound,
+		"/keys/elements?limit=x": http.StatusBadRequest,
+	} {
+		if code := get(t, h, path, nil); code != want {
+			t.Fatalf("%s: expected status %d, have %d", path, want, code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Denied", "1")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("auth: expected forbidden, have %d", rec.Code)
+	}
+	if code := get(t, New(nil), "/", nil); code != http.StatusForbidden {
+		t.Fatalf("auth: expected forbidden without auth, have %d", code)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package llrbtest provides a model-based checker for llrb trees. It
+// runs random operation sequences against a tree and a sorted slice,
+// comparing the results and checking the tree invariants after every
+// operation. It is meant for packages defining their own elements or
+// building on llrb, to test their integration.
+package llrbtest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+// Config configures a Run.
+type Config struct {
+	// Gen returns a random element. Elements should collide often
+	// enough for replacements and successful deletes to occur.
+	Gen func(*rand.Rand) llrb.Element
+
+	Ops  int   // number of operations, 1000 if 0
+	Seed int64 // seed of the random operation sequence
+	Opts []llrb.Option
+}
+
+// Run performs cfg.Ops random operations on a tree created with
+// cfg.Opts and on a sorted slice model, and fails tb with the seed and
+// operation number at the first difference, invariant violation, or
+// change to an earlier version of the tree.
+func Run(tb testing.TB, cfg Config) {
+	tb.Helper()
+	if err := run(cfg); err != nil {
+		tb.Fatalf("llrbtest: seed %d: %v", cfg.Seed, err)
+	}
+}
+
+func run(cfg Config) (err error) {
+	var (
+		i    int
+		desc string
+	)
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("op %d %s: panic: %v", i, desc, v)
+		}
+	}()
+
+	ops := cfg.Ops
+	if ops == 0 {
+		ops = 1000
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	tree, m := llrb.New(cfg.Opts...), model(nil)
+
+	for i = 0; i < ops; i++ {
+		prev, prevModel := tree, m.clone()
+		txn := tree.Txn()
+		desc = ""
+		switch op := rng.Intn(10); {
+		case op < 4:
+			e := cfg.Gen(rng)
+			desc = fmt.Sprintf("Insert(%v)", e)
+			txn.Insert(e)
+			m = m.insert(e)
+		case op < 7:
+			e := cfg.Gen(rng)
+			desc = fmt.Sprintf("Delete(%v)", e)
+			txn.Delete(e)
+			m = m.delete(e)
+		case op == 7:
+			desc = "DeleteMin()"
+			txn.DeleteMin()
+			if len(m) > 0 {
+				m = m[1:]
+			}
+		case op == 8:
+			desc = "DeleteMax()"
+			txn.DeleteMax()
+			if len(m) > 0 {
+				m = m[:len(m)-1]
+			}
+		default:
+			e := cfg.Gen(rng)
+			desc = fmt.Sprintf("Get(%v)", e)
+			if got, want := txn.Get(e), m.get(e); !same(got, want) {
+				return fmt.Errorf("op %d %s: got %v, want %v", i, desc, got, want)
+			}
+		}
+		tree = txn.Commit()
+
+		if err := compare(tree, m); err != nil {
+			return fmt.Errorf("op %d %s: %v", i, desc, err)
+		}
+		if err := compare(prev, prevModel); err != nil {
+			return fmt.Errorf("op %d %s: earlier version changed: %v", i, desc, err)
+		}
+		if err := checkRange(tree, m, cfg.Gen(rng), cfg.Gen(rng)); err != nil {
+			return fmt.Errorf("op %d %s: %v", i, desc, err)
+		}
+	}
+	return nil
+}
+
+// compare checks the invariants of tree and that it holds the elements
+// of m.
+func compare(tree *llrb.Tree, m model) error {
+	if err := tree.Check(); err != nil {
+		return err
+	}
+	if tree.Len() != len(m) {
+		return fmt.Errorf("length %d, want %d", tree.Len(), len(m))
+	}
+	i := 0
+	var err error
+	tree.ForEach(func(e llrb.Element) bool {
+		if !same(e, m[i]) {
+			err = fmt.Errorf("element %d is %v, want %v", i, e, m[i])
+		}
+		i++
+		return err != nil
+	})
+	return err
+}
+
+// checkRange checks that tree.Range over [lo, hi) visits the elements
+// of m in that interval.
+func checkRange(tree *llrb.Tree, m model, lo, hi llrb.Element) error {
+	if lo.Compare(hi) > 0 {
+		lo, hi = hi, lo
+	}
+	a, b := m.search(lo), m.search(hi)
+	if a > b {
+		return fmt.Errorf("inconsistent Compare for %v and %v", lo, hi)
+	}
+	want := m[a:b]
+	i := 0
+	var err error
+	tree.Range(lo, hi, func(e llrb.Element) bool {
+		if i >= len(want) || !same(e, want[i]) {
+			err = fmt.Errorf("Range(%v, %v) visited %v at %d", lo, hi, e, i)
+		}
+		i++
+		return err != nil
+	})
+	if err == nil && i != len(want) {
+		err = fmt.Errorf("Range(%v, %v) visited %d elements, want %d", lo, hi, i, len(want))
+	}
+	return err
+}
+
+// same reports whether a and b are both nil or compare equal and are
+// deeply equal. Unlike ==, it works for elements of non-comparable
+// types such as slices.
+func same(a, b llrb.Element) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Compare(b) == 0 && reflect.DeepEqual(a, b)
+}
+
+// model is a sorted slice of elements.
+type model []llrb.Element
+
+// search returns the index of the first element not less than e.
+func (m model) search(e llrb.Element) int {
+	return sort.Search(len(m), func(i int) bool { return m[i].Compare(e) >= 0 })
+}
+
+func (m model) get(e llrb.Element) llrb.Element {
+	if i := m.search(e); i < len(m) && m[i].Compare(e) == 0 {
+		return m[i]
+	}
+	return nil
+}
+
+func (m model) insert(e llrb.Element) model {
+	i := m.search(e)
+	if i < len(m) && m[i].Compare(e) == 0 {
+		m[i] = e
+		return m
+	}
+	m = append(m, nil)
+	copy(m[i+1:], m[i:])
+	m[i] = e
+	return m
+}
+
+func (m model) delete(e llrb.Element) model {
+	if i := m.search(e); i < len(m) && m[i].Compare(e) == 0 {
+		return append(m[:i], m[i+1:]...)
+	}
+	return m
+}
+
+func (m model) clone() model { return append(model(nil), m...) }
@@ -0,0 +1,54 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrbtest
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+type key int
+
+func (k key) Compare(e llrb.Element) int { return int(k) - int(e.(key)) }
+
+func genKey(r *rand.Rand) llrb.Element { return key(r.Intn(200)) }
+
+func TestRun(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		Run(t, Config{Gen: genKey, Seed: seed})
+		Run(t, Config{Gen: genKey, Seed: seed, Opts: []llrb.Option{llrb.WithCompaction(0.5)}})
+	}
+}
+
+// sliceKey is a key of a non-comparable type, ordered by its first
+// value.
+type sliceKey []int
+
+func (k sliceKey) Compare(e llrb.Element) int { return k[0] - e.(sliceKey)[0] }
+
+func TestRunNonComparable(t *testing.T) {
+	gen := func(r *rand.Rand) llrb.Element { return sliceKey{r.Intn(50), 1} }
+	Run(t, Config{Gen: gen, Ops: 500})
+}
+
+// badKey orders inconsistently for some keys, which the model detects.
+type badKey int
+
+func (k badKey) Compare(e llrb.Element) int {
+	if k == 13 {
+		return -1
+	}
+	return int(k) - int(e.(badKey))
+}
+
+func TestRunDetects(t *testing.T) {
+	err := run(Config{Gen: func(r *rand.Rand) llrb.Element { return badKey(r.Intn(20)) }, Ops: 500})
+	if err == nil || !strings.HasPrefix(err.Error(), "op ") {
+		t.Fatalf("llrbtest: expected failure for inconsistent Compare, have %v", err)
+	}
+}
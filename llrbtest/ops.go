@@ -0,0 +1,196 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrbtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mars9/llrb"
+)
+
+// OpKind is the kind of an operation in an operation sequence.
+type OpKind byte
+
+// Operation kinds. Keys are mapped to elements by the key function
+// passed to Replay.
+const (
+	OpInsert    OpKind = iota // Insert(key(A))
+	OpDelete                  // Delete(key(A))
+	OpDeleteMin               // DeleteMin()
+	OpDeleteMax               // DeleteMax()
+	OpGet                     // Get(key(A))
+	OpRange                   // Range(key(A), key(B)), ordered if inverted
+	numOpKinds
+)
+
+var opNames = [...]string{"insert", "delete", "deletemin", "deletemax", "get", "range"}
+
+// nkeys returns the number of keys taken by operations of kind k.
+func (k OpKind) nkeys() int {
+	switch k {
+	case OpDeleteMin, OpDeleteMax:
+		return 0
+	case OpRange:
+		return 2
+	}
+	return 1
+}
+
+// Op is an operation in an operation sequence.
+type Op struct {
+	Kind OpKind
+	A, B uint8 // keys, unused ones are 0
+}
+
+// String returns the operation as a script line, for example
+// "insert 17" or "range 3 9".
+func (op Op) String() string {
+	switch op.Kind.nkeys() {
+	case 0:
+		return opNames[op.Kind]
+	case 1:
+		return fmt.Sprintf("%s %d", opNames[op.Kind], op.A)
+	}
+	return fmt.Sprintf("%s %d %d", opNames[op.Kind], op.A, op.B)
+}
+
+// Decode maps data to an operation sequence. Every byte string decodes
+// to a valid sequence, so Decode can turn the input of a fuzz target
+// into operations: each operation is a kind byte, taken modulo the
+// number of kinds, followed by a byte per key. A trailing incomplete
+// operation is dropped.
+func Decode(data []byte) []Op {
+	var ops []Op
+	for len(data) > 0 {
+		op := Op{Kind: OpKind(data[0] % byte(numOpKinds))}
+		n := op.Kind.nkeys()
+		if len(data) < 1+n {
+			break
+		}
+		if n > 0 {
+			op.A = data[1]
+		}
+		if n > 1 {
+			op.B = data[2]
+		}
+		ops = append(ops, op)
+		data = data[1+n:]
+	}
+	return ops
+}
+
+// Encode returns the byte string Decode maps to ops, for adding a
+// sequence to a fuzz corpus.
+func Encode(ops []Op) []byte {
+	var data []byte
+	for _, op := range ops {
+		data = append(data, byte(op.Kind))
+		switch op.Kind.nkeys() {
+		case 1:
+			data = append(data, op.A)
+		case 2:
+			data = append(data, op.A, op.B)
+		}
+	}
+	return data
+}
+
+// FormatScript returns ops as a script of one operation per line.
+func FormatScript(ops []Op) string {
+	var b strings.Builder
+	for _, op := range ops {
+		b.WriteString(op.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ParseScript parses a script written by FormatScript. Blank lines and
+// lines starting with # are ignored.
+func ParseScript(script string) ([]Op, error) {
+	var ops []Op
+	for i, line := range strings.Split(script, "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		op, ok := Op{}, false
+		for k, name := range opNames {
+			if f[0] == name {
+				op.Kind, ok = OpKind(k), true
+			}
+		}
+		if !ok || len(f) != 1+op.Kind.nkeys() {
+			return nil, fmt.Errorf("llrbtest: line %d: bad operation %q", i+1, line)
+		}
+		keys := []*uint8{&op.A, &op.B}
+		for j, s := range f[1:] {
+			var k uint8
+			if _, err := fmt.Sscan(s, &k); err != nil {
+				return nil, fmt.Errorf("llrbtest: line %d: bad key %q", i+1, s)
+			}
+			*keys[j] = k
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// Replay applies ops to a tree created with opts, one transaction per
+// operation, and to a sorted slice model, with key mapping keys to
+// elements. It returns an error naming the first operation after which
+// the tree differs from the model, violates an invariant, or an earlier
+// version of it changed.
+func Replay(ops []Op, key func(uint8) llrb.Element, opts ...llrb.Option) (err error) {
+	var i int
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("op %d %v: panic: %v", i, ops[i], v)
+		}
+	}()
+
+	tree, m := llrb.New(opts...), model(nil)
+	for i = range ops {
+		op := ops[i]
+		prev, prevModel := tree, m.clone()
+		txn := tree.Txn()
+		switch op.Kind {
+		case OpInsert:
+			txn.Insert(key(op.A))
+			m = m.insert(key(op.A))
+		case OpDelete:
+			txn.Delete(key(op.A))
+			m = m.delete(key(op.A))
+		case OpDeleteMin:
+			txn.DeleteMin()
+			if len(m) > 0 {
+				m = m[1:]
+			}
+		case OpDeleteMax:
+			txn.DeleteMax()
+			if len(m) > 0 {
+				m = m[:len(m)-1]
+			}
+		case OpGet:
+			if got, want := txn.Get(key(op.A)), m.get(key(op.A)); !same(got, want) {
+				return fmt.Errorf("op %d %v: got %v, want %v", i, op, got, want)
+			}
+		case OpRange:
+			if err := checkRange(txn.Commit(), m, key(op.A), key(op.B)); err != nil {
+				return fmt.Errorf("op %d %v: %v", i, op, err)
+			}
+		}
+		tree = txn.Commit()
+
+		if err := compare(tree, m); err != nil {
+			return fmt.Errorf("op %d %v: %v", i, op, err)
+		}
+		if err := compare(prev, prevModel); err != nil {
+			return fmt.Errorf("op %d %v: earlier version changed: %v", i, op, err)
+		}
+	}
+	return nil
+}
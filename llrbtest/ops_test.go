@@ -0,0 +1,57 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrbtest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+func intKey(k uint8) llrb.Element { return key(k) }
+
+func TestOpsRoundTrip(t *testing.T) {
+	data := []byte{0, 5, 8, 1, 2, 3, 4, 9, 5, 3, 8, 11, 42}
+	ops := Decode(data)
+	want := []Op{
+		{Kind: OpInsert, A: 5}, {Kind: OpDeleteMin}, {Kind: OpDelete, A: 2},
+		{Kind: OpDeleteMax}, {Kind: OpGet, A: 9}, {Kind: OpRange, A: 3, B: 8},
+		{Kind: OpRange, A: 42},
+	}
+	if len(ops) != len(want)-1 || !reflect.DeepEqual(ops, want[:len(want)-1]) {
+		t.Fatalf("decode: expected %v, have %v", want[:len(want)-1], ops)
+	}
+	if enc := Encode(want[:3]); !bytes.Equal(enc, []byte{0, 5, 2, 1, 2}) {
+		t.Fatalf("encode: unexpected %v", enc)
+	}
+	if got := Decode(Encode(want)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("encode: expected %v after round trip, have %v", want, got)
+	}
+
+	script := FormatScript(want)
+	parsed, err := ParseScript("# replay\n" + script)
+	if err != nil || !reflect.DeepEqual(parsed, want) {
+		t.Fatalf("parse script: expected %v, have %v (%v)", want, parsed, err)
+	}
+	if _, err := ParseScript("insert"); err == nil {
+		t.Fatalf("parse script: expected error for missing key")
+	}
+
+	if err := Replay(ops, intKey); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+}
+
+func FuzzReplay(f *testing.F) {
+	f.Add(Encode([]Op{{Kind: OpInsert, A: 1}, {Kind: OpInsert, A: 2}, {Kind: OpDelete, A: 1}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := Decode(data)
+		if err := Replay(ops, intKey); err != nil {
+			t.Fatalf("%v\nscript:\n%s", err, FormatScript(ops))
+		}
+	})
+}
@@ -0,0 +1,154 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Rows is the part of *sql.Rows used by LoadRows.
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// LoadRows returns a balanced tree configured by opts holding the
+// elements fn maps the rows of a query result to. fn is called once per
+// row and typically calls rows.Scan. The elements are sorted, skipped if
+// the query already returned them in order, and merged into the tree in
+// batches instead of by repeated insertion, so apart from the tree only
+// a batch of elements is held in memory. Of elements that compare equal
+// the last one is kept, as with Insert. LoadRows stops at the first
+// error returned by fn or rows and does not close rows.
+func LoadRows(rows Rows, fn func(Rows) (Element, error), opts ...Option) (*Tree, error) {
+	l := newLoader(opts)
+	for rows.Next() {
+		elem, err := fn(rows)
+		if err != nil {
+			return nil, err
+		}
+		l.add(elem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return l.finish(), nil
+}
+
+// loadBatch is the number of elements LoadRows and LoadJSON sort and
+// merge into the tree at a time.
+const loadBatch = 64 << 10
+
+// loader builds a tree from elements in any order by sorting and
+// merging them in batches of loadBatch.
+type loader struct {
+	t     *Tree
+	root  *node
+	batch []Element
+}
+
+func newLoader(opts []Option) *loader {
+	return &loader{t: New(opts...)}
+}
+
+// add adds elem to the current batch, merging the batch once it is full.
+func (l *loader) add(elem Element) {
+	if l.batch = append(l.batch, elem); len(l.batch) == loadBatch {
+		l.root, _ = l.root.union(sortUnique(l.batch), nil, l.t.aug)
+		l.batch = l.batch[:0]
+	}
+}
+
+// finish merges the last batch and returns the tree.
+func (l *loader) finish() *Tree {
+	l.root, _ = l.root.union(sortUnique(l.batch), nil, l.t.aug)
+	t := l.t
+	t.root, t.size = l.root, l.root.len()
+	if t.bloomBits > 0 {
+		t.bloom = buildBloom(t)
+	}
+	return t
+}
+
+// LoadJSON returns a balanced tree configured by opts holding the
+// elements of the JSON array dec is positioned on. fn is called once per
+// array value and typically calls dec.Decode. The elements are sorted
+// and merged into the tree in batches, so apart from the tree only a
+// batch of elements is held in memory. Of elements that compare equal
+// the last one is kept, as with Insert. LoadJSON stops at the first
+// error returned by fn or dec and leaves dec positioned after the
+// array.
+func LoadJSON(dec *json.Decoder, fn func(*json.Decoder) (Element, error), opts ...Option) (*Tree, error) {
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('[') {
+		return nil, fmt.Errorf("llrb: expected JSON array, have %v", tok)
+	}
+	l := newLoader(opts)
+	for dec.More() {
+		elem, err := fn(dec)
+		if err != nil {
+			return nil, err
+		}
+		l.add(elem)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return l.finish(), nil
+}
+
+// fromElements returns a balanced tree configured by opts holding elems,
+// which are sorted in place. Of elements that compare equal the last
+// one is kept.
+func fromElements(elems []Element, opts ...Option) *Tree {
+	elems = sortUnique(elems)
+	t := New(opts...)
+	t.root = build(elems, t.aug)
+	t.size = len(elems)
+	if t.bloomBits > 0 {
+		t.bloom = buildBloom(t)
+	}
+	return t
+}
+
+// sortUnique sorts elems in place and removes all but the last of
+// elements that compare equal.
+func sortUnique(elems []Element) []Element {
+	cmp := func(a, b Element) int { return a.Compare(b) }
+	if !slices.IsSortedFunc(elems, cmp) {
+		// Sort positions alongside the elements rather than sorting
+		// stably, which is several times slower.
+		type entry struct {
+			elem Element
+			pos  int
+		}
+		entries := make([]entry, len(elems))
+		for i, elem := range elems {
+			entries[i] = entry{elem, i}
+		}
+		slices.SortFunc(entries, func(a, b entry) int {
+			if c := a.elem.Compare(b.elem); c != 0 {
+				return c
+			}
+			return a.pos - b.pos
+		})
+		for i, e := range entries {
+			elems[i] = e.elem
+		}
+	}
+	n := 0
+	for i, elem := range elems {
+		if i+1 < len(elems) && elem.Compare(elems[i+1]) == 0 {
+			continue
+		}
+		elems[n] = elem
+		n++
+	}
+	return elems[:n]
+}
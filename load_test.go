@@ -0,0 +1,111 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var _ Rows = (*sql.Rows)(nil)
+
+// fakeRows yields one int column per row.
+type fakeRows struct {
+	vals []int
+	i    int
+	err  error
+}
+
+func (r *fakeRows) Next() bool { r.i++; return r.i <= len(r.vals) }
+func (r *fakeRows) Err() error { return r.err }
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = r.vals[r.i-1]
+	return nil
+}
+
+func scanInt(rows Rows) (Element, error) {
+	var i int
+	err := rows.Scan(&i)
+	return compInt(i), err
+}
+
+func TestLoadRows(t *testing.T) {
+	batches := make([]int, 2*loadBatch+10) // merged in three batches
+	for i := range batches {
+		batches[i] = (i * 7919) % (loadBatch + 3)
+	}
+	for _, vals := range [][]int{nil, {1, 2, 3}, {5, 3, 9, 3, 1, 7, 5}, batches} {
+		tree, err := LoadRows(&fakeRows{vals: vals}, scanInt, WithStats())
+		if err != nil {
+			t.Fatalf("load rows: %v", err)
+		}
+		checkTree(t, "load rows", tree)
+		want := &Tree{}
+		txn := want.Txn()
+		for _, v := range vals {
+			txn.Insert(compInt(v))
+		}
+		want = txn.Commit()
+		if !reflect.DeepEqual(elements(want), elements(tree)) || tree.stats == nil {
+			t.Fatalf("load rows: expected %v, have %v", elements(want), elements(tree))
+		}
+	}
+
+	boom := errors.New("boom")
+	if _, err := LoadRows(&fakeRows{vals: []int{1}, err: boom}, scanInt); err != boom {
+		t.Fatalf("load rows: expected rows error, have %v", err)
+	}
+	fail := func(Rows) (Element, error) { return nil, boom }
+	if _, err := LoadRows(&fakeRows{vals: []int{1}}, fail); err != boom {
+		t.Fatalf("load rows: expected mapping error, have %v", err)
+	}
+}
+
+func decodeInt(dec *json.Decoder) (Element, error) {
+	var i int
+	err := dec.Decode(&i)
+	return compInt(i), err
+}
+
+func TestLoadJSON(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[")
+	want := map[compInt]bool{}
+	for i := 0; i < 2*loadBatch+10; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		v := compInt((i * 7919) % (loadBatch + 3))
+		want[v] = true
+		b.WriteString(strconv.Itoa(int(v)))
+	}
+	b.WriteString("] 42")
+
+	dec := json.NewDecoder(strings.NewReader(b.String()))
+	tree, err := LoadJSON(dec, decodeInt)
+	if err != nil {
+		t.Fatalf("load json: %v", err)
+	}
+	checkTree(t, "load json", tree)
+	if tree.Len() != len(want) {
+		t.Fatalf("load json: expected %d elements, have %d", len(want), tree.Len())
+	}
+	var next int
+	if err := dec.Decode(&next); err != nil || next != 42 {
+		t.Fatalf("load json: expected decoder after the array, have %v, %v", next, err)
+	}
+
+	for _, in := range []string{`{"a": 1}`, `[1, 2, "x"]`, `[1, 2`} {
+		if _, err := LoadJSON(json.NewDecoder(strings.NewReader(in)), decodeInt); err == nil {
+			t.Fatalf("load json: expected error for %q", in)
+		}
+	}
+}
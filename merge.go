@@ -0,0 +1,131 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "io"
+
+// mergeBatch is the number of stream elements MergeStream merges into
+// the tree at a time.
+const mergeBatch = 64 << 10
+
+// A Resolver combines two elements that compare equal, old from the
+// tree being merged into and new from the elements merged, into the
+// element kept. The result must compare equal to both. A nil Resolver
+// keeps new.
+type Resolver func(old, new Element) Element
+
+// apply returns the element kept of old and new.
+func (r Resolver) apply(old, new Element) Element {
+	if r == nil {
+		return new
+	}
+	return r(old, new)
+}
+
+// MergeStream returns a new version of base holding its elements and the
+// elements returned by next until it returns false. The stream must be
+// in ascending order; of consecutive elements that compare equal the
+// last one is kept, and stream elements replace the elements of base
+// they compare equal to, as with Insert. The stream is read in batches
+// that are merged by splitting them along the tree, so subtrees of base
+// that receive no new elements are shared rather than copied. base is
+// not modified and the new tree has its options. MergeStream panics if
+// the stream is not in ascending order.
+func MergeStream(base *Tree, next func() (Element, bool)) *Tree {
+	return MergeStreamFunc(base, next, nil)
+}
+
+// MergeStreamFunc is like MergeStream, but combines elements that
+// compare equal with resolve: consecutive stream elements as
+// resolve(earlier, later), and an element of base and a stream element
+// as resolve(base element, stream element).
+func MergeStreamFunc(base *Tree, next func() (Element, bool), resolve Resolver) *Tree {
+	var root *node
+	if base != nil {
+		root = base.root
+	}
+	batch := make([]Element, 0, 1024)
+	var last Element
+	for {
+		elem, ok := next()
+		if ok && last != nil {
+			switch c := elem.Compare(last); {
+			case c < 0:
+				panic("llrb: stream not in ascending order")
+			case c == 0:
+				last = resolve.apply(last, elem)
+				batch[len(batch)-1] = last
+				continue
+			}
+		}
+		if !ok || len(batch) == mergeBatch {
+			root, _ = root.union(batch, resolve, base.kinds())
+			batch = batch[:0]
+		}
+		if !ok {
+			break
+		}
+		batch = append(batch, elem)
+		last = elem
+	}
+	return base.derive(root)
+}
+
+// Union returns a tree holding the elements of a and b. Elements that
+// compare equal are combined by resolve(element of a, element of b),
+// or taken from b if resolve is nil. The elements of the smaller tree
+// are merged into the larger one, whose subtrees that receive no
+// elements are shared rather than copied, provided both trees keep the
+// same augmentations. Neither tree is modified, and the new tree has
+// the options of a.
+func Union(a, b *Tree, resolve Resolver) *Tree {
+	small, large := b, a
+	if a.Len() < b.Len() && a.kinds() == b.kinds() {
+		small, large = a, b
+		r := resolve
+		resolve = func(old, new Element) Element { return r.apply(new, old) }
+	}
+	var root *node
+	if large != nil {
+		root = large.root
+	}
+	elems := make([]Element, 0, small.Len())
+	small.ForEach(func(elem Element) bool {
+		elems = append(elems, elem)
+		return false
+	})
+	root, _ = root.union(elems, resolve, a.kinds())
+	return a.derive(root)
+}
+
+// MergeAll returns a balanced tree holding the elements of all trees,
+// merging them in a single pass over cursors on each, which is cheaper
+// than repeated Union for many small trees. Of elements that compare
+// equal, the one of the last tree in trees is kept. The trees are not
+// modified, and the new tree has the options of the first one.
+func MergeAll(trees []*Tree) *Tree {
+	if len(trees) == 0 {
+		return &Tree{}
+	}
+	var h mergeHeap
+	size := 0
+	for i, t := range trees {
+		c := t.Cursor()
+		h.push(func() (Element, error) {
+			if elem := c.Next(); elem != nil {
+				return elem, nil
+			}
+			c.Close()
+			return nil, io.EOF
+		}, i)
+		size += t.Len()
+	}
+	elems := make([]Element, 0, size)
+	h.drain(func(elem Element) error {
+		elems = append(elems, elem)
+		return nil
+	})
+	return trees[0].derive(build(elems, trees[0].kinds()))
+}
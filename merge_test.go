@@ -0,0 +1,174 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sliceStream returns a stream function over elems.
+func sliceStream(elems ...Element) func() (Element, bool) {
+	return func() (Element, bool) {
+		if len(elems) == 0 {
+			return nil, false
+		}
+		elem := elems[0]
+		elems = elems[1:]
+		return elem, true
+	}
+}
+
+func TestMergeStream(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 1000} {
+		base := randomTree(n, 4*n+1)
+		before := elements(base)
+		for _, m := range []int{0, 1, 10, 1000, mergeBatch + 10} {
+			var stream []Element
+			set := map[compInt]bool{}
+			for _, e := range before {
+				set[e.(compInt)] = true
+			}
+			for i := 0; i < m; i++ {
+				e := compInt(i * (4*n + 1) / (m + 1))
+				stream = append(stream, e)
+				set[e] = true
+			}
+			merged := MergeStream(base, sliceStream(stream...))
+			checkTree(t, "merge stream", merged)
+			if merged.Len() != len(set) {
+				t.Fatalf("merge stream: expected %d elements, have %d", len(set), merged.Len())
+			}
+			for _, e := range elements(merged) {
+				if !set[e.(compInt)] {
+					t.Fatalf("merge stream: unexpected element %v", e)
+				}
+			}
+			if have := elements(base); len(before) > 0 && !reflect.DeepEqual(before, have) {
+				t.Fatalf("merge stream: base tree modified")
+			}
+		}
+	}
+
+	merged := MergeStream(nil, sliceStream(compInt(1), compInt(2), compInt(2), compInt(3)))
+	if have := elements(merged); !reflect.DeepEqual(have, []Element{compInt(1), compInt(2), compInt(3)}) {
+		t.Fatalf("merge stream: unexpected elements %v", have)
+	}
+
+	defer func() {
+		if v, _ := recover().(string); !strings.Contains(v, "ascending") {
+			t.Fatalf("merge stream: expected order panic, got %q", v)
+		}
+	}()
+	MergeStream(nil, sliceStream(compInt(2), compInt(1)))
+}
+
+func TestMergeStreamSharing(t *testing.T) {
+	base := randomTree(10000, 40000)
+	merged := MergeStream(base, sliceStream(compInt(-2), compInt(-1)))
+	if merged.Len() != base.Len()+2 {
+		t.Fatalf("merge stream: expected %d elements, have %d", base.Len()+2, merged.Len())
+	}
+	if live := LiveNodes(base, merged); live > base.Len()+100 {
+		t.Fatalf("merge stream: expected shared nodes, have %d live for %d elements", live, base.Len())
+	}
+}
+
+// stamped is an element ordered by key, carrying a timestamp.
+type stamped struct{ key, ts int }
+
+func (s stamped) Compare(elem Element) int { return s.key - elem.(stamped).key }
+
+// newest keeps the element with the later timestamp.
+func newest(old, new Element) Element {
+	if old.(stamped).ts > new.(stamped).ts {
+		return old
+	}
+	return new
+}
+
+func TestMergeStreamFunc(t *testing.T) {
+	txn := New().Txn()
+	txn.Insert(stamped{1, 5})
+	txn.Insert(stamped{2, 1})
+	base := txn.Commit()
+
+	tree := MergeStreamFunc(base, sliceStream(stamped{1, 3}, stamped{2, 4}, stamped{2, 2}, stamped{3, 1}), newest)
+	want := []Element{stamped{1, 5}, stamped{2, 4}, stamped{3, 1}}
+	if have := elements(tree); !reflect.DeepEqual(have, want) {
+		t.Fatalf("merge stream func: expected %v, have %v", want, have)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	ta, tb := New().Txn(), New().Txn()
+	for i := 0; i < 100; i++ {
+		ta.Insert(stamped{i, i % 3})
+	}
+	for i := 50; i < 60; i++ {
+		tb.Insert(stamped{i, 1})
+	}
+	a, b := ta.Commit(), tb.Commit()
+
+	for _, u := range []*Tree{Union(a, b, newest), Union(b, a, func(old, new Element) Element { return newest(new, old) })} {
+		if err := u.Check(); err != nil {
+			t.Fatalf("union: %v", err)
+		}
+		if u.Len() != 100 {
+			t.Fatalf("union: expected 100 elements, have %d", u.Len())
+		}
+		for i := 0; i < 100; i++ {
+			want := stamped{i, i % 3}
+			if i >= 50 && i < 60 && i%3 < 1 {
+				want.ts = 1
+			}
+			if e := u.Get(stamped{key: i}); e != want {
+				t.Fatalf("union: expected %v, have %v", want, e)
+			}
+		}
+	}
+	if e := Union(a, b, nil).Get(stamped{key: 51}); e != (stamped{51, 1}) {
+		t.Fatalf("union: expected element of b without resolver, have %v", e)
+	}
+	if e := Union(b, a, nil).Get(stamped{key: 51}); e != (stamped{51, 0}) {
+		t.Fatalf("union: expected element of a without resolver, have %v", e)
+	}
+	if u := Union(nil, b, nil); u.Len() != 10 {
+		t.Fatalf("union: expected 10 elements with nil tree, have %d", u.Len())
+	}
+}
+
+func TestMergeAll(t *testing.T) {
+	if tree := MergeAll(nil); tree.Len() != 0 {
+		t.Fatalf("merge all: expected empty tree, have %d elements", tree.Len())
+	}
+
+	var trees []*Tree
+	seen := make(map[int]int)
+	for i := 0; i < 20; i++ {
+		txn := New().Txn()
+		for j := 0; j < 30; j++ {
+			k := (i*7 + j*13) % 300
+			txn.Insert(stamped{k, i})
+			seen[k] = i
+		}
+		trees = append(trees, txn.Commit())
+	}
+	trees = append(trees, nil, New())
+
+	tree := MergeAll(trees)
+	if err := tree.Check(); err != nil {
+		t.Fatalf("merge all: %v", err)
+	}
+	if tree.Len() != len(seen) {
+		t.Fatalf("merge all: expected %d elements, have %d", len(seen), tree.Len())
+	}
+	for k, ts := range seen {
+		if e := tree.Get(stamped{key: k}); e != (stamped{k, ts}) {
+			t.Fatalf("merge all: expected element of last tree %v, have %v", stamped{k, ts}, e)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "time"
+
+// Meta holds the bookkeeping recorded for an element of a tree created
+// with WithMeta.
+type Meta struct {
+	Inserted time.Time // commit that first inserted the element
+	Updated  time.Time // commit that last inserted or replaced it
+}
+
+// WithMeta records the time of the commit that inserted each element
+// and of the one that last replaced it, queryable through Meta and
+// ModifiedSince. The times are taken from now, or time.Now if now is
+// nil, once per commit. The records are kept in a companion tree that
+// is updated at Commit from the changes of the transaction, which
+// therefore tracks changes, so each commit costs an extra operation per
+// change. Elements of trees built in bulk, as by LoadRows or Restore,
+// have no records.
+func WithMeta(now func() time.Time) Option {
+	if now == nil {
+		now = time.Now
+	}
+	return func(t *Tree) {
+		t.metaNow = now
+	}
+}
+
+// metaEntry is an element of the companion tree holding metadata.
+type metaEntry struct {
+	elem Element
+	meta Meta
+}
+
+// Compare implements the Element interface.
+func (e metaEntry) Compare(elem Element) int {
+	return e.elem.Compare(elem.(metaEntry).elem)
+}
+
+// stampMeta records the pending changes of t in the metadata of its
+// tree.
+func (t *Txn) stampMeta() {
+	if t.tree.metaNow == nil || len(t.changes) == 0 {
+		return
+	}
+	now := t.tree.metaNow()
+	txn := t.tree.meta.Txn()
+	for _, ch := range t.changes {
+		key := metaEntry{elem: ch.Elem}
+		if ch.Op == ChangeDelete {
+			txn.Delete(key)
+			continue
+		}
+		e := metaEntry{elem: ch.Elem, meta: Meta{Inserted: now, Updated: now}}
+		if old, ok := txn.Get(key).(metaEntry); ok {
+			e.meta.Inserted = old.meta.Inserted
+		}
+		txn.Insert(e)
+	}
+	t.tree.meta = txn.Commit()
+}
+
+// Meta returns the metadata recorded for elem and whether elem is stored
+// in a tree created with WithMeta.
+func (t *Tree) Meta(elem Element) (Meta, bool) {
+	if t == nil || t.metaNow == nil || t.Get(elem) == nil {
+		return Meta{}, false
+	}
+	e, ok := t.meta.Get(metaEntry{elem: elem}).(metaEntry)
+	return e.meta, ok
+}
+
+// ModifiedSince performs fn, in ascending order, on the elements of a
+// tree created with WithMeta that were inserted or replaced by a commit
+// at or after ts. It visits the metadata of every element and looks up
+// each match in the tree, so its cost is linear in the size of the
+// tree. A boolean is returned indicating whether the traversal was
+// interrupted by fn returning true.
+func (t *Tree) ModifiedSince(ts time.Time, fn Visitor) bool {
+	if t == nil || t.metaNow == nil {
+		return false
+	}
+	// Trees cut out of others, as by Extract, share the metadata of the
+	// whole tree, which may hold any number of elements not in t, so
+	// every match has to be looked up.
+	return t.meta.ForEach(func(elem Element) bool {
+		e := elem.(metaEntry)
+		if e.meta.Updated.Before(ts) || t.Get(e.elem) == nil {
+			return false
+		}
+		return fn(e.elem)
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMeta(t *testing.T) {
+	clock := time.Unix(0, 0)
+	tick := func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	t1, t2, t3 := time.Unix(1, 0), time.Unix(2, 0), time.Unix(3, 0)
+
+	tree := New(WithMeta(tick))
+	txn := tree.Txn()
+	for i := compInt(0); i < 10; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+	txn = tree.Txn()
+	txn.Insert(compInt(3))
+	txn.Append(compInt(10))
+	txn.Delete(compInt(5))
+	tree = txn.Commit()
+	txn = tree.Txn()
+	txn.BufferInsert(compInt(7))
+	latest := txn.Commit()
+
+	for elem, want := range map[compInt]Meta{
+		0:  {t1, t1},
+		3:  {t1, t2},
+		7:  {t1, t3},
+		10: {t2, t2},
+	} {
+		if m, ok := latest.Meta(elem); !ok || m != want {
+			t.Fatalf("meta: expected %v for %v, have %v (%v)", want, elem, m, ok)
+		}
+	}
+	if _, ok := latest.Meta(compInt(5)); ok {
+		t.Fatalf("meta: unexpected metadata for deleted element")
+	}
+	if m, _ := tree.Meta(compInt(7)); m.Updated != t1 {
+		t.Fatalf("meta: earlier version changed, have %v", m)
+	}
+
+	var have []Element
+	latest.ModifiedSince(t2, func(elem Element) bool {
+		have = append(have, elem)
+		return false
+	})
+	if want := []Element{compInt(3), compInt(7), compInt(10)}; !reflect.DeepEqual(want, have) {
+		t.Fatalf("modified since: expected %v, have %v", want, have)
+	}
+	have = nil
+	latest.Extract(compInt(5), compInt(20)).ModifiedSince(t2, func(elem Element) bool {
+		have = append(have, elem)
+		return false
+	})
+	if want := []Element{compInt(7), compInt(10)}; !reflect.DeepEqual(want, have) {
+		t.Fatalf("modified since: expected %v in extracted tree, have %v", want, have)
+	}
+
+	// Restored elements have no metadata, so an extracted tree can hold
+	// as many elements as there are records without holding all of them.
+	var buf bytes.Buffer
+	if err := randomTree(10, 10).Persist(&buf, intCodec{}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	loaded, err := Restore(&buf, intCodec{}, WithMeta(tick))
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	txn = loaded.Txn()
+	for i := compInt(100); i < 105; i++ {
+		txn.Insert(i)
+	}
+	have = nil
+	txn.Commit().Extract(compInt(6), compInt(101)).ModifiedSince(t1, func(elem Element) bool {
+		have = append(have, elem)
+		return false
+	})
+	if want := []Element{compInt(100)}; !reflect.DeepEqual(want, have) {
+		t.Fatalf("modified since: expected %v in extracted tree, have %v", want, have)
+	}
+
+	if _, ok := randomTree(10, 100).Meta(compInt(1)); ok {
+		t.Fatalf("meta: unexpected metadata without WithMeta")
+	}
+}
@@ -30,41 +30,108 @@ const (
 	black = true
 )
 
+// A probe records the work done by a single tree operation. A nil probe
+// records nothing.
+type probe struct {
+	depth    int // number of nodes visited
+	compares int // number of Compare calls
+}
+
+func (p *probe) visit() {
+	if p != nil {
+		p.depth++
+	}
+}
+
+// compare returns elem.Compare(n.elem), deciding by the digests alone
+// where the tree keeps them and they differ.
+func (p *probe) compare(elem Element, n *node) int {
+	if n.aug != nil && n.aug.kinds&augDigest != 0 {
+		if c := compareDigest(elem, n.aug.digest); c != 0 {
+			return c
+		}
+	}
+	if p != nil {
+		p.compares++
+	}
+	return elem.Compare(n.elem)
+}
+
 type node struct {
 	elem  Element
 	right *node
 	left  *node
 	color bool
+	size  int // number of elements in the subtree rooted at the node
+
+	aug *augment // nil unless the tree keeps augmentations
+}
+
+// newNode returns a red leaf holding elem and keeping the augmentations
+// k.
+func newNode(elem Element, k augKinds) *node {
+	n := alloc(k)
+	n.elem = elem
+	n.update()
+	return n
 }
 
 func (n *node) copy() *node {
+	if n.aug != nil {
+		a := &augNode{node: *n, augment: *n.aug}
+		a.aug = &a.augment
+		return &a.node
+	}
 	return &node{
 		elem:  n.elem,
 		left:  n.left,
 		right: n.right,
 		color: n.color,
+		size:  n.size,
+	}
+}
+
+// len returns the number of elements in the subtree rooted at n.
+func (n *node) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// update recomputes the subtree size and augmentations of n after its
+// element or children changed.
+func (n *node) update() {
+	n.size = 1 + n.left.len() + n.right.len()
+	if n.aug != nil {
+		n.updateAug()
 	}
 }
 
 func (n *node) rotateLeft() *node {
-	root := n.right
+	root := n.right.copy()
 	n.right = root.left
 	root.left = n
 	root.color = n.color
 	n.color = red
+	n.update()
+	root.update()
 	return root
 }
 
 func (n *node) rotateRight() *node {
-	root := n.left
+	root := n.left.copy()
 	n.left = root.right
 	root.right = n
 	root.color = n.color
 	n.color = red
+	n.update()
+	root.update()
 	return root
 }
 
 func (n *node) flipColors() {
+	n.left, n.right = n.left.copy(), n.right.copy()
 	n.color = !n.color
 	n.left.color = !n.left.color
 	n.right.color = !n.right.color
@@ -109,9 +176,10 @@ func (n *node) moveRedRight() *node {
 	return n
 }
 
-func (n *node) find(elem Element) *node {
+func (n *node) find(elem Element, p *probe) *node {
 	for n != nil {
-		switch cmp := elem.Compare(n.elem); {
+		p.visit()
+		switch cmp := p.compare(elem, n); {
 		case cmp == 0:
 			return n
 		case cmp < 0:
@@ -123,23 +191,74 @@ func (n *node) find(elem Element) *node {
 	return n
 }
 
-func (n *node) insert(elem Element) (*node, int) {
+// floor returns the node holding the largest element not greater than
+// elem, or nil if there is no such element.
+func (n *node) floor(elem Element) *node {
+	var f *node
+	for n != nil {
+		switch cmp := elem.Compare(n.elem); {
+		case cmp == 0:
+			return n
+		case cmp < 0:
+			n = n.left
+		default:
+			f, n = n, n.right
+		}
+	}
+	return f
+}
+
+// ceil returns the node holding the smallest element not less than
+// elem, or nil if there is no such element.
+func (n *node) ceil(elem Element) *node {
+	var c *node
+	for n != nil {
+		switch cmp := elem.Compare(n.elem); {
+		case cmp == 0:
+			return n
+		case cmp < 0:
+			c, n = n, n.left
+		default:
+			n = n.right
+		}
+	}
+	return c
+}
+
+// below returns the node holding the largest element less than elem,
+// or nil if there is no such element.
+func (n *node) below(elem Element) *node {
+	var b *node
+	for n != nil {
+		if elem.Compare(n.elem) <= 0 {
+			n = n.left
+		} else {
+			b, n = n, n.right
+		}
+	}
+	return b
+}
+
+func (n *node) insert(elem Element, k augKinds, p *probe) (*node, int) {
 	if n == nil {
-		return &node{elem: elem}, 1
+		return newNode(elem, k), 1
 	} else if n.elem == nil {
 		n.elem = elem
+		n.update()
 		return n, 1
 	}
+	p.visit()
 
 	root, m := n.copy(), 0 // recursive branch copy
-	switch cmp := elem.Compare(root.elem); {
+	switch cmp := p.compare(elem, root); {
 	case cmp == 0:
 		root.elem = elem
 	case cmp < 0:
-		root.left, m = root.left.insert(elem)
+		root.left, m = root.left.insert(elem, k, p)
 	default:
-		root.right, m = root.right.insert(elem)
+		root.right, m = root.right.insert(elem, k, p)
 	}
+	root.update()
 
 	if root.right.isRed() && !root.left.isRed() {
 		root = root.rotateLeft()
@@ -153,21 +272,26 @@ func (n *node) insert(elem Element) (*node, int) {
 	return root, m
 }
 
-func (n *node) deleteMin() (*node, int) {
+func (n *node) deleteMin(p *probe) (*node, int) {
+	p.visit()
 	if n.left == nil {
 		return nil, -1
 	}
+	n = n.copy() // recursive branch copy
 	if !n.left.isRed() && !n.left.left.isRed() {
 		n = n.moveRedLeft()
 	}
 	var m int
-	n.left, m = n.left.deleteMin()
+	n.left, m = n.left.deleteMin(p)
+	n.update()
 
 	root := n.fixUp()
 	return root, m
 }
 
-func (n *node) deleteMax() (*node, int) {
+func (n *node) deleteMax(p *probe) (*node, int) {
+	p.visit()
+	n = n.copy() // recursive branch copy
 	if n.left != nil && n.left.isRed() {
 		n = n.rotateRight()
 	}
@@ -178,7 +302,8 @@ func (n *node) deleteMax() (*node, int) {
 		n = n.moveRedRight()
 	}
 	var m int
-	n.right, m = n.right.deleteMax()
+	n.right, m = n.right.deleteMax(p)
+	n.update()
 
 	root := n.fixUp()
 	return root, m
@@ -196,35 +321,37 @@ func (n *node) max() *node {
 	return n
 }
 
-func (n *node) delete(elem Element) (*node, int) {
+func (n *node) delete(elem Element, p *probe) (*node, int) {
+	p.visit()
 	root, m := n.copy(), 0 // recursive branch copy
 
-	if elem.Compare(root.elem) < 0 {
+	if p.compare(elem, root) < 0 {
 		if root.left != nil {
 			if !root.left.isRed() && !root.left.left.isRed() {
 				root = root.moveRedLeft()
 			}
-			root.left, m = root.left.delete(elem)
+			root.left, m = root.left.delete(elem, p)
 		}
 	} else {
 		if root.left.isRed() {
 			root = root.rotateRight()
 		}
-		if root.right == nil && elem.Compare(root.elem) == 0 {
+		if root.right == nil && p.compare(elem, root) == 0 {
 			return nil, -1
 		}
 		if root.right != nil {
 			if !root.right.isRed() && !root.right.left.isRed() {
 				root = root.moveRedRight()
 			}
-			if elem.Compare(root.elem) == 0 {
+			if p.compare(elem, root) == 0 {
 				root.elem = root.right.min().elem
-				root.right, m = root.right.deleteMin()
+				root.right, m = root.right.deleteMin(p)
 			} else {
-				root.right, m = root.right.delete(elem)
+				root.right, m = root.right.delete(elem, p)
 			}
 		}
 	}
+	root.update()
 
 	root = root.fixUp()
 	return root, m
@@ -264,3 +391,115 @@ func (n *node) doRange(lo, hi Element, fn Visitor) (done bool) {
 	}
 	return done
 }
+
+// build returns a balanced tree holding the sorted elems. The tree has
+// the largest black height possible for its size, so most nodes are
+// black and the tree is as shallow as a complete binary tree. Its nodes
+// keep the augmentations k.
+func build(elems []Element, k augKinds) *node {
+	bh := 0
+	for n := len(elems) + 1; n > 1; n >>= 1 {
+		bh++
+	}
+	return buildHeight(elems, bh, k)
+}
+
+// buildHeight returns a tree of black height bh holding the sorted
+// elems. The number of elements must lie within [2^bh-1, 3^bh-1].
+func buildHeight(elems []Element, bh int, k augKinds) *node {
+	n := len(elems)
+	if n == 0 {
+		return nil
+	}
+
+	// max is the largest number of elements a subtree of black height
+	// bh-1 can hold.
+	max := 0
+	for i := 1; i < bh && max < n; i++ {
+		max = 3*max + 2
+	}
+	if n-1 <= 2*max {
+		i := n / 2
+		root := alloc(k)
+		root.elem = elems[i]
+		root.left = buildHeight(elems[:i], bh-1, k)
+		root.right = buildHeight(elems[i+1:], bh-1, k)
+		root.color = black
+		root.update()
+		return root
+	}
+
+	// Too many elements for a 2-node, so the root is a black node with
+	// a red left child and the elements are split into three subtrees.
+	a, b := n/3, (n-1)/3
+	left := alloc(k)
+	left.elem = elems[a]
+	left.left = buildHeight(elems[:a], bh-1, k)
+	left.right = buildHeight(elems[a+1:a+1+b], bh-1, k)
+	left.color = red
+	left.update()
+	root := alloc(k)
+	root.elem = elems[a+1+b]
+	root.left = left
+	root.right = buildHeight(elems[a+2+b:], bh-1, k)
+	root.color = black
+	root.update()
+	return root
+}
+
+// doBounded performs fn on all elements in [lo, hi), in descending
+// order if reverse is set. A nil bound leaves that end of the interval
+// open.
+func (n *node) doBounded(lo, hi Element, reverse bool, fn Visitor) bool {
+	if n == nil {
+		return false
+	}
+	aboveLo := lo == nil || lo.Compare(n.elem) <= 0
+	belowHi := hi == nil || hi.Compare(n.elem) > 0
+
+	first, second := n.left, n.right
+	descendFirst, descendSecond := aboveLo, belowHi
+	if reverse {
+		first, second = second, first
+		descendFirst, descendSecond = descendSecond, descendFirst
+	}
+	if descendFirst && first.doBounded(lo, hi, reverse, fn) {
+		return true
+	}
+	if aboveLo && belowHi && fn(n.elem) {
+		return true
+	}
+	return descendSecond && second.doBounded(lo, hi, reverse, fn)
+}
+
+// at returns the node holding the element of rank i, counting from 0,
+// in the subtree rooted at n, or nil if there is no such element.
+func (n *node) at(i int) *node {
+	for n != nil {
+		switch l := n.left.len(); {
+		case i < l:
+			n = n.left
+		case i == l:
+			return n
+		default:
+			i -= l + 1
+			n = n.right
+		}
+	}
+	return nil
+}
+
+// rank returns the number of elements less than elem in the subtree
+// rooted at n.
+func (n *node) rank(elem Element) int {
+	r := 0
+	for n != nil {
+		if elem.Compare(n.elem) <= 0 {
+			n = n.left
+		} else {
+			r += n.left.len() + 1
+			n = n.right
+		}
+	}
+	return r
+}
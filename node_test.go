@@ -47,6 +47,16 @@ func (n *node) isBalanced(black int) bool {
 	return n.left.isBalanced(black) && n.right.isBalanced(black)
 }
 
+func (n *node) isSized() bool {
+	if n == nil {
+		return true
+	}
+	if n.size != 1+n.left.len()+n.right.len() {
+		return false
+	}
+	return n.left.isSized() && n.right.isSized()
+}
+
 func (n *node) isBST(min, max Element) bool {
 	if n == nil {
 		return true
@@ -106,6 +116,16 @@ func makeTree(desc string) (n *node) {
 	if n.left == nil && n.right == nil {
 		n = nil
 	}
+
+	var size func(*node) int
+	size = func(n *node) int {
+		if n == nil {
+			return 0
+		}
+		n.size = 1 + size(n.left) + size(n.right)
+		return n.size
+	}
+	size(n)
 	return n
 }
 
@@ -0,0 +1,97 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Rank returns the number of elements in the tree less than elem. The
+// rank is found through the subtree sizes kept in every node, so the
+// cost is logarithmic in the size of the tree. elem need not be stored
+// in the tree.
+func (t *Tree) Rank(elem Element) int {
+	if t == nil {
+		return 0
+	}
+	return t.root.rank(elem)
+}
+
+// At returns the element of rank i, counting from 0, or nil if i is out
+// of range. Like Rank, the cost is logarithmic in the size of the tree.
+func (t *Tree) At(i int) Element {
+	if t == nil || i < 0 {
+		return nil
+	}
+	if n := t.root.at(i); n != nil {
+		return n.elem
+	}
+	return nil
+}
+
+// ForEachSampled performs fn on every k-th element of the tree in
+// ascending order, the elements of rank 0, k, 2k and so on. Subtrees
+// holding no sampled rank are skipped through the subtree sizes, so a
+// scan of n elements costs about n/k times the height of the tree. A k
+// less than 1 is treated as 1. A boolean is returned indicating whether
+// the traversal was interrupted by fn returning true.
+func (t *Tree) ForEachSampled(k int, fn Visitor) bool {
+	if t == nil {
+		return false
+	}
+	if k < 1 {
+		k = 1
+	}
+	return t.root.doSampled(0, k, fn)
+}
+
+// doSampled performs fn on the elements of the subtree rooted at n whose
+// rank, offset by base, is a multiple of k.
+func (n *node) doSampled(base, k int, fn Visitor) bool {
+	if n == nil {
+		return false
+	}
+	// next is the first sampled rank not less than base.
+	next := (base + k - 1) / k * k
+	if next >= base+n.size {
+		return false
+	}
+	l := n.left.len()
+	if n.left.doSampled(base, k, fn) {
+		return true
+	}
+	if (base+l)%k == 0 && fn(n.elem) {
+		return true
+	}
+	return n.right.doSampled(base+l+1, k, fn)
+}
+
+// MinInRange returns the smallest element over the interval [from, to),
+// or nil if there is none. The cost is logarithmic in the size of the
+// tree. If to is less than from MinInRange will panic.
+func (t *Tree) MinInRange(from, to Element) Element {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	if t == nil {
+		return nil
+	}
+	if n := t.root.ceil(from); n != nil && to.Compare(n.elem) > 0 {
+		return n.elem
+	}
+	return nil
+}
+
+// MaxInRange returns the largest element over the interval [from, to),
+// or nil if there is none. The cost is logarithmic in the size of the
+// tree. If to is less than from MaxInRange will panic.
+func (t *Tree) MaxInRange(from, to Element) Element {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	if t == nil {
+		return nil
+	}
+	if n := t.root.below(to); n != nil && from.Compare(n.elem) <= 0 {
+		return n.elem
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	var tree *Tree
+	if r := tree.Rank(compInt(1)); r != 0 {
+		t.Fatalf("rank: expected 0 for nil tree, have %d", r)
+	}
+
+	tree = randomTree(500, 1000)
+	elems := elements(tree)
+	for q := compInt(-1); q <= 1001; q++ {
+		want := 0
+		for _, e := range elems {
+			if e.Compare(q) < 0 {
+				want++
+			}
+		}
+		if r := tree.Rank(q); r != want {
+			t.Fatalf("rank: expected %d for %d, have %d", want, q, r)
+		}
+	}
+}
+
+func TestAt(t *testing.T) {
+	var tree *Tree
+	if e := tree.At(0); e != nil {
+		t.Fatalf("at: expected nil for nil tree, have %v", e)
+	}
+
+	tree = randomTree(500, 1000)
+	for i, want := range elements(tree) {
+		if e := tree.At(i); e != want {
+			t.Fatalf("at: expected %v at %d, have %v", want, i, e)
+		}
+		if r := tree.Rank(want); r != i {
+			t.Fatalf("at: expected rank %d for %v, have %d", i, want, r)
+		}
+	}
+	if e := tree.At(-1); e != nil {
+		t.Fatalf("at: expected nil at -1, have %v", e)
+	}
+	if e := tree.At(500); e != nil {
+		t.Fatalf("at: expected nil at 500, have %v", e)
+	}
+}
+
+func TestForEachSampled(t *testing.T) {
+	tree := randomTree(1000, 4000)
+	all := elements(tree)
+	for _, k := range []int{0, 1, 2, 7, 100, 999, 1000, 5000} {
+		var have []Element
+		tree.ForEachSampled(k, func(elem Element) bool {
+			have = append(have, elem)
+			return false
+		})
+		step := k
+		if step < 1 {
+			step = 1
+		}
+		var want []Element
+		for i := 0; i < len(all); i += step {
+			want = append(want, all[i])
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Fatalf("sampled: expected %v for k=%d, have %v", want, k, have)
+		}
+	}
+
+	var n int
+	if !tree.ForEachSampled(10, func(Element) bool { n++; return n == 3 }) || n != 3 {
+		t.Fatalf("sampled: expected interrupted traversal after 3 elements, have %d", n)
+	}
+	if (*Tree)(nil).ForEachSampled(1, nil) {
+		t.Fatalf("sampled: expected nil tree traversal not to be interrupted")
+	}
+}
+
+func TestMinMaxInRange(t *testing.T) {
+	tree := randomTree(200, 1000)
+	all := elements(tree)
+	for from := compInt(-5); from < 1005; from += 7 {
+		for _, to := range []compInt{from, from + 1, from + 10, from + 300} {
+			var want []Element
+			for _, e := range all {
+				if e.Compare(from) >= 0 && e.Compare(to) < 0 {
+					want = append(want, e)
+				}
+			}
+			min, max := tree.MinInRange(from, to), tree.MaxInRange(from, to)
+			if len(want) == 0 {
+				if min != nil || max != nil {
+					t.Fatalf("in range: expected nil for [%v, %v), have %v, %v", from, to, min, max)
+				}
+				continue
+			}
+			if min != want[0] || max != want[len(want)-1] {
+				t.Fatalf("in range: expected %v, %v for [%v, %v), have %v, %v", want[0], want[len(want)-1], from, to, min, max)
+			}
+		}
+	}
+	if (*Tree)(nil).MinInRange(compInt(0), compInt(1)) != nil || (*Tree)(nil).MaxInRange(compInt(0), compInt(1)) != nil {
+		t.Fatalf("in range: expected nil for nil tree")
+	}
+}
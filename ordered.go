@@ -0,0 +1,109 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "cmp"
+
+// Ordered is an Element holding a value of an ordered type. Values are
+// compared with cmp.Compare, so NaNs sort before all other floats.
+type Ordered[T cmp.Ordered] struct {
+	V T
+}
+
+// Compare implements the Element interface.
+func (o Ordered[T]) Compare(elem Element) int {
+	return cmp.Compare(o.V, elem.(Ordered[T]).V)
+}
+
+// OrderedTree is an immutable set of values of an ordered type, kept in
+// a Tree of Ordered elements. A nil *OrderedTree is an empty set.
+type OrderedTree[T cmp.Ordered] struct {
+	tree *Tree
+}
+
+// NewOrdered returns an empty OrderedTree configured by opts.
+func NewOrdered[T cmp.Ordered](opts ...Option) *OrderedTree[T] {
+	return &OrderedTree[T]{tree: New(opts...)}
+}
+
+// Tree returns the underlying tree of Ordered elements.
+func (t *OrderedTree[T]) Tree() *Tree {
+	if t == nil {
+		return nil
+	}
+	return t.tree
+}
+
+// Has reports whether v is in the set.
+func (t *OrderedTree[T]) Has(v T) bool {
+	return t.Tree().Get(Ordered[T]{v}) != nil
+}
+
+// Min returns the smallest value in the set. The boolean is false if
+// the set is empty.
+func (t *OrderedTree[T]) Min() (T, bool) {
+	return value[T](t.Tree().Min())
+}
+
+// Max returns the largest value in the set. The boolean is false if the
+// set is empty.
+func (t *OrderedTree[T]) Max() (T, bool) {
+	return value[T](t.Tree().Max())
+}
+
+// Len returns the number of values in the set.
+func (t *OrderedTree[T]) Len() int { return t.Tree().Len() }
+
+// Range performs fn on all values over the interval [from, to) in
+// ascending order, as Tree.Range.
+func (t *OrderedTree[T]) Range(from, to T, fn func(T) (done bool)) bool {
+	return t.Tree().Range(Ordered[T]{from}, Ordered[T]{to}, func(elem Element) bool {
+		return fn(elem.(Ordered[T]).V)
+	})
+}
+
+// ForEach performs fn on all values in ascending order, as
+// Tree.ForEach.
+func (t *OrderedTree[T]) ForEach(fn func(T) (done bool)) bool {
+	return t.Tree().ForEach(func(elem Element) bool {
+		return fn(elem.(Ordered[T]).V)
+	})
+}
+
+// OrderedTxn is a transaction on an OrderedTree.
+type OrderedTxn[T cmp.Ordered] struct {
+	txn *Txn
+}
+
+// Txn starts a new transaction that can be used to mutate the set.
+func (t *OrderedTree[T]) Txn() *OrderedTxn[T] {
+	return &OrderedTxn[T]{txn: t.Tree().Txn()}
+}
+
+// Insert adds v to the set.
+func (t *OrderedTxn[T]) Insert(v T) { t.txn.Insert(Ordered[T]{v}) }
+
+// Delete removes v from the set.
+func (t *OrderedTxn[T]) Delete(v T) { t.txn.Delete(Ordered[T]{v}) }
+
+// Has reports whether v is in the set.
+func (t *OrderedTxn[T]) Has(v T) bool { return t.txn.Get(Ordered[T]{v}) != nil }
+
+// Len returns the number of values in the set.
+func (t *OrderedTxn[T]) Len() int { return t.txn.Len() }
+
+// Commit finalizes the transaction and returns the new set.
+func (t *OrderedTxn[T]) Commit() *OrderedTree[T] {
+	return &OrderedTree[T]{tree: t.txn.Commit()}
+}
+
+// value returns the value held by the Ordered element elem, if any.
+func value[T cmp.Ordered](elem Element) (T, bool) {
+	if elem == nil {
+		var zero T
+		return zero, false
+	}
+	return elem.(Ordered[T]).V, true
+}
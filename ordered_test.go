@@ -0,0 +1,53 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestOrdered(t *testing.T) {
+	var empty *OrderedTree[string]
+	if _, ok := empty.Min(); ok || empty.Has("a") || empty.Len() != 0 {
+		t.Fatalf("ordered: expected nil tree to be empty")
+	}
+
+	strs := NewOrdered[string]()
+	txn := strs.Txn()
+	for _, s := range []string{"pear", "apple", "fig", "apple", "kiwi"} {
+		txn.Insert(s)
+	}
+	txn.Delete("kiwi")
+	strs = txn.Commit()
+
+	var have []string
+	strs.ForEach(func(s string) bool {
+		have = append(have, s)
+		return false
+	})
+	if want := []string{"apple", "fig", "pear"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("ordered: expected %v, have %v", want, have)
+	}
+	if min, _ := strs.Min(); min != "apple" || !strs.Has("fig") || strs.Has("kiwi") {
+		t.Fatalf("ordered: unexpected set contents")
+	}
+
+	floats := NewOrdered[float64]()
+	ftxn := floats.Txn()
+	for _, f := range []float64{2.5, math.NaN(), -1, math.NaN()} {
+		ftxn.Insert(f)
+	}
+	floats = ftxn.Commit()
+	if min, _ := floats.Min(); floats.Len() != 3 || !math.IsNaN(min) {
+		t.Fatalf("ordered: expected NaN to sort first in 3 values, have %v in %d", min, floats.Len())
+	}
+	n := 0
+	floats.Range(-1, 3, func(float64) bool { n++; return false })
+	if n != 2 {
+		t.Fatalf("ordered: expected 2 values in range, have %d", n)
+	}
+}
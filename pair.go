@@ -0,0 +1,79 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Pair is an immutable pair of trees holding the same elements in two
+// orders. Primary is ordered by the elements themselves, Secondary by
+// the elements returned by the Pair's key function. Both trees are only
+// ever changed together through a PairTxn, so a *Pair published through
+// a single pointer, for example a sync/atomic.Pointer, is never seen
+// with one index updated and the other not.
+type Pair struct {
+	Primary   *Tree
+	Secondary *Tree
+
+	by func(Element) Element
+}
+
+// NewPair returns an empty Pair whose secondary order is given by by.
+// For every element e stored in the pair, by(e) is stored in the
+// secondary tree; by(e) must be unique among the elements stored.
+func NewPair(by func(Element) Element) *Pair {
+	return &Pair{Primary: &Tree{}, Secondary: &Tree{}, by: by}
+}
+
+// PairTxn is a transaction on a Pair. Like Txn it is not thread safe.
+type PairTxn struct {
+	primary   *Txn
+	secondary *Txn
+	by        func(Element) Element
+}
+
+// Txn starts a new transaction that can be used to mutate both trees.
+func (p *Pair) Txn() *PairTxn {
+	return &PairTxn{
+		primary:   p.Primary.Txn(),
+		secondary: p.Secondary.Txn(),
+		by:        p.by,
+	}
+}
+
+// Get returns the match of elem in the primary tree.
+func (t *PairTxn) Get(elem Element) Element {
+	return t.primary.Get(elem)
+}
+
+// Insert inserts elem into both trees, replacing the element it
+// matches in the primary tree and that element's secondary entry.
+func (t *PairTxn) Insert(elem Element) {
+	if old := t.primary.Get(elem); old != nil {
+		t.secondary.Delete(t.by(old))
+	}
+	t.primary.Insert(elem)
+	t.secondary.Insert(t.by(elem))
+}
+
+// Delete deletes the element matching elem in the primary tree from
+// both trees.
+func (t *PairTxn) Delete(elem Element) {
+	old := t.primary.Get(elem)
+	if old == nil {
+		return
+	}
+	t.primary.Delete(elem)
+	t.secondary.Delete(t.by(old))
+}
+
+// Len returns the number of elements stored in the pair.
+func (t *PairTxn) Len() int { return t.primary.Len() }
+
+// Commit finalizes the transaction and returns the new Pair.
+func (t *PairTxn) Commit() *Pair {
+	return &Pair{
+		Primary:   t.primary.Commit(),
+		Secondary: t.secondary.Commit(),
+		by:        t.by,
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+// player is ordered by name, byScore orders players by score.
+type player struct {
+	name  string
+	score int
+}
+
+func (p player) Compare(elem Element) int {
+	o := elem.(player)
+	switch {
+	case p.name < o.name:
+		return -1
+	case p.name > o.name:
+		return 1
+	}
+	return 0
+}
+
+type byScore player
+
+func (p byScore) Compare(elem Element) int {
+	o := elem.(byScore)
+	if p.score != o.score {
+		return p.score - o.score
+	}
+	return player(p).Compare(player(o))
+}
+
+func TestPair(t *testing.T) {
+	pair := NewPair(func(e Element) Element { return byScore(e.(player)) })
+	txn := pair.Txn()
+	txn.Insert(player{"ann", 30})
+	txn.Insert(player{"bob", 10})
+	txn.Insert(player{"cid", 20})
+	txn.Insert(player{"bob", 40}) // replaces bob's entry in both trees
+	txn.Delete(player{name: "cid"})
+	txn.Delete(player{name: "dan"})
+	next := txn.Commit()
+
+	var names []string
+	next.Secondary.ForEach(func(e Element) bool {
+		names = append(names, e.(byScore).name)
+		return false
+	})
+	if want := []string{"ann", "bob"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("pair: expected %v by score, have %v", want, names)
+	}
+	if next.Primary.Len() != 2 || next.Secondary.Len() != 2 {
+		t.Fatalf("pair: expected 2 elements in each tree, have %d and %d",
+			next.Primary.Len(), next.Secondary.Len())
+	}
+	if pair.Primary.Len() != 0 || pair.Secondary.Len() != 0 {
+		t.Fatalf("pair: expected original pair unchanged")
+	}
+}
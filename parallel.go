@@ -0,0 +1,107 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ParallelRange performs fn on all values stored in the tree over the
+// interval [from, to) using up to workers goroutines. The interval is
+// divided by rank into sub-ranges of nearly equal size, each traversed
+// in ascending order by its own goroutine, so fn sees the elements of
+// different sub-ranges in no particular order and must be safe for
+// concurrent use. Once fn returns true the traversals stop as soon as
+// possible, and a boolean is returned indicating whether this happened.
+// If to is less than from ParallelRange will panic.
+func (t *Tree) ParallelRange(from, to Element, workers int, fn Visitor) bool {
+	var done atomic.Bool
+	t.parallel(from, to, workers, func(_ int, elem Element) bool {
+		if done.Load() {
+			return true
+		}
+		if fn(elem) {
+			done.Store(true)
+			return true
+		}
+		return false
+	})
+	return done.Load()
+}
+
+// ParallelMap returns fn applied to all values stored in the tree over
+// the interval [from, to), in ascending order of the values. The calls
+// to fn are spread over up to workers goroutines as by ParallelRange,
+// and fn must be safe for concurrent use. If to is less than from
+// ParallelMap will panic.
+func ParallelMap[R any](t *Tree, from, to Element, workers int, fn func(Element) R) []R {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	lo, hi := t.Rank(from), t.Rank(to)
+	if lo >= hi {
+		return nil
+	}
+	results := make([]R, hi-lo)
+	t.parallel(from, to, workers, func(rank int, elem Element) bool {
+		results[rank-lo] = fn(elem)
+		return false
+	})
+	return results
+}
+
+// parallel performs fn on the values over [from, to) together with
+// their rank, on up to workers goroutines.
+func (t *Tree) parallel(from, to Element, workers int, fn func(rank int, elem Element) bool) {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	if t == nil || t.root == nil {
+		return
+	}
+	lo, hi := t.root.rank(from), t.root.rank(to)
+	n := hi - lo
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		a, b := lo+k*n/workers, lo+(k+1)*n/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rank := a
+			t.root.doRank(a, b, func(elem Element) bool {
+				rank++
+				return fn(rank-1, elem)
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+// doRank performs fn on the elements with rank in [lo, hi) in the
+// subtree rooted at n, in ascending order.
+func (n *node) doRank(lo, hi int, fn Visitor) bool {
+	if n == nil || lo >= hi {
+		return false
+	}
+	l := n.left.len()
+	if lo < l && n.left.doRank(lo, hi, fn) {
+		return true
+	}
+	if lo <= l && l < hi && fn(n.elem) {
+		return true
+	}
+	if lo < l+1 {
+		lo = l + 1
+	}
+	return n.right.doRank(lo-l-1, hi-l-1, fn)
+}
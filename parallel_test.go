@@ -0,0 +1,63 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParallelRange(t *testing.T) {
+	tree := randomTree(1000, 5000)
+	var want []Element
+	tree.Range(compInt(100), compInt(4000), func(e Element) bool {
+		want = append(want, e)
+		return false
+	})
+
+	for _, workers := range []int{0, 1, 3, 8, 5000} {
+		var (
+			mu   sync.Mutex
+			seen = make(map[Element]int)
+		)
+		if tree.ParallelRange(compInt(100), compInt(4000), workers, func(e Element) bool {
+			mu.Lock()
+			seen[e]++
+			mu.Unlock()
+			return false
+		}) {
+			t.Fatalf("parallel range: unexpected interruption")
+		}
+		if len(seen) != len(want) {
+			t.Fatalf("parallel range: expected %d elements with %d workers, have %d", len(want), workers, len(seen))
+		}
+		for _, e := range want {
+			if seen[e] != 1 {
+				t.Fatalf("parallel range: element %v visited %d times", e, seen[e])
+			}
+		}
+
+		have := ParallelMap(tree, compInt(100), compInt(4000), workers, func(e Element) Element { return e })
+		if !reflect.DeepEqual(want, have) {
+			t.Fatalf("parallel map: expected %v with %d workers, have %v", want, workers, have)
+		}
+	}
+
+	if !tree.ParallelRange(compInt(0), compInt(5000), 4, func(e Element) bool { return e == want[10] }) {
+		t.Fatalf("parallel range: expected interruption")
+	}
+	if r := ParallelMap(tree, compInt(10), compInt(10), 4, func(e Element) int { return 0 }); r != nil {
+		t.Fatalf("parallel map: expected no results for empty range, have %v", r)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("parallel map: expected panic for inverted range")
+			}
+		}()
+		ParallelMap(tree, compInt(20), compInt(10), 4, func(e Element) int { return 0 })
+	}()
+}
@@ -0,0 +1,102 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package percentile implements an exact percentile tracker backed by
+// an immutable llrb tree used as an order-statistic tree.
+package percentile
+
+import (
+	"math"
+	"sync"
+
+	"github.com/mars9/llrb"
+)
+
+// sample is an observation recorded by a Tracker.
+type sample struct {
+	v   float64
+	seq uint64 // orders equal observations
+}
+
+// Compare implements the llrb.Element interface.
+func (s sample) Compare(elem llrb.Element) int {
+	o := elem.(sample)
+	switch {
+	case s.v < o.v:
+		return -1
+	case s.v > o.v:
+		return 1
+	case s.seq < o.seq:
+		return -1
+	case s.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+// Tracker records observations and answers percentile queries exactly.
+// It is safe for concurrent use; queries do not block recording.
+type Tracker struct {
+	mu      sync.Mutex // serializes modifications
+	seq     uint64
+	ring    []sample // observations in recording order if bounded
+	next    int      // ring index of the oldest observation once full
+	samples llrb.Atomic
+}
+
+// New returns a Tracker holding at most max observations. Once full, the
+// oldest observation is evicted for every new one. A max of zero or less
+// leaves the tracker unbounded.
+func New(max int) *Tracker {
+	t := &Tracker{}
+	if max > 0 {
+		t.ring = make([]sample, 0, max)
+	}
+	return t
+}
+
+// Observe records the observation v. NaN observations are ignored.
+func (t *Tracker) Observe(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	s := sample{v: v, seq: t.seq}
+	txn := t.samples.Load().Txn()
+	switch {
+	case t.ring == nil:
+	case len(t.ring) < cap(t.ring):
+		t.ring = append(t.ring, s)
+	default:
+		txn.Delete(t.ring[t.next])
+		t.ring[t.next] = s
+		t.next = (t.next + 1) % len(t.ring)
+	}
+	txn.Insert(s)
+	t.samples.Store(txn.Commit())
+}
+
+// Percentile returns the p-th percentile, 0 <= p <= 100, of the
+// observations held, using the nearest-rank method. The boolean is false
+// if no observations are held. The cost is logarithmic in the number of
+// observations.
+func (t *Tracker) Percentile(p float64) (float64, bool) {
+	samples := t.samples.Load()
+	n := samples.Len()
+	if n == 0 {
+		return 0, false
+	}
+	i := int(math.Ceil(p/100*float64(n))) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= n {
+		i = n - 1
+	}
+	return samples.At(i).(sample).v, true
+}
+
+// Len returns the number of observations held.
+func (t *Tracker) Len() int { return t.samples.Load().Len() }
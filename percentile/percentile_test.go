@@ -0,0 +1,43 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package percentile
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTracker(t *testing.T) {
+	tr := New(0)
+	if _, ok := tr.Percentile(50); ok {
+		t.Fatalf("tracker: unexpected percentile for empty tracker")
+	}
+	for _, i := range rand.Perm(100) {
+		tr.Observe(float64(i + 1))
+	}
+	for _, tc := range []struct{ p, want float64 }{
+		{0, 1}, {1, 1}, {50, 50}, {95, 95}, {99, 99}, {99.5, 100}, {100, 100},
+	} {
+		if v, _ := tr.Percentile(tc.p); v != tc.want {
+			t.Fatalf("tracker: expected p%v = %v, have %v", tc.p, tc.want, v)
+		}
+	}
+}
+
+func TestTrackerBounded(t *testing.T) {
+	tr := New(10)
+	for i := 0; i < 25; i++ {
+		tr.Observe(float64(i % 5))
+	}
+	if tr.Len() != 10 {
+		t.Fatalf("tracker: expected 10 observations, have %d", tr.Len())
+	}
+	for i := 0; i < 10; i++ {
+		tr.Observe(100)
+	}
+	if v, _ := tr.Percentile(0); v != 100 || tr.Len() != 10 {
+		t.Fatalf("tracker: expected old observations evicted, have p0 = %v with %d held", v, tr.Len())
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Query describes a traversal over a range of a tree. Queries are built
+// by chaining methods on the value returned by Tree.Query, for example
+//
+//	tree.Query().From(a).To(b).Reverse().Limit(100).Each(fn)
+//
+// Each method returns a modified copy, so a partially built Query can
+// be reused.
+type Query struct {
+	tree     *Tree
+	from, to Element
+	reverse  bool
+	limit    int
+}
+
+// Query returns a Query visiting all elements of the tree in ascending
+// order.
+func (t *Tree) Query() Query { return Query{tree: t} }
+
+// From restricts the query to elements not less than from.
+func (q Query) From(from Element) Query {
+	q.from = from
+	return q
+}
+
+// To restricts the query to elements less than to.
+func (q Query) To(to Element) Query {
+	q.to = to
+	return q
+}
+
+// Reverse makes the query visit elements in descending order.
+func (q Query) Reverse() Query {
+	q.reverse = !q.reverse
+	return q
+}
+
+// Limit makes the query visit at most n elements. A limit of 0 or less
+// removes the limit.
+func (q Query) Limit(n int) Query {
+	q.limit = n
+	return q
+}
+
+// Each performs fn on the elements selected by the query. If to is less
+// than from Each will panic. A boolean is returned indicating whether
+// the traversal was interrupted by fn returning true.
+func (q Query) Each(fn Visitor) bool {
+	if q.tree == nil || q.tree.root == nil {
+		return false
+	}
+	if q.from != nil && q.to != nil && q.from.Compare(q.to) > 0 {
+		panic("inverted range")
+	}
+	if q.limit > 0 {
+		n, visit, interrupted := 0, fn, false
+		fn = func(elem Element) bool {
+			n++
+			interrupted = visit(elem)
+			return interrupted || n == q.limit
+		}
+		q.tree.root.doBounded(q.from, q.to, q.reverse, fn)
+		return interrupted
+	}
+	return q.tree.root.doBounded(q.from, q.to, q.reverse, fn)
+}
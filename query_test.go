@@ -0,0 +1,62 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 100; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+
+	ints := func(lo, hi, step int) (s compInts) {
+		for i := lo; i != hi; i += step {
+			s = append(s, compInt(i))
+		}
+		return s
+	}
+
+	base := tree.Query()
+	for _, test := range []struct {
+		q    Query
+		want compInts
+	}{
+		{base, ints(0, 100, 1)},
+		{base.Reverse(), ints(99, -1, -1)},
+		{base.From(compInt(90)), ints(90, 100, 1)},
+		{base.To(compInt(10)), ints(0, 10, 1)},
+		{base.From(compInt(10)).To(compInt(20)), ints(10, 20, 1)},
+		{base.From(compInt(10)).To(compInt(20)).Reverse(), ints(19, 9, -1)},
+		{base.From(compInt(10)).Reverse().Limit(3), ints(99, 96, -1)},
+		{base.To(compInt(50)).Reverse().Limit(5), ints(49, 44, -1)},
+		{base.Limit(200), ints(0, 100, 1)},
+		{base.From(compInt(20)).To(compInt(20)), nil},
+	} {
+		var result compInts
+		if test.q.Each(func(elem Element) bool {
+			result = append(result, elem.(compInt))
+			return false
+		}) {
+			t.Fatalf("query: unexpected interrupted traversal")
+		}
+		if !reflect.DeepEqual(test.want, result) {
+			t.Fatalf("query: expected values %v, have %v", test.want, result)
+		}
+	}
+
+	var n int
+	if !base.Limit(10).Each(func(Element) bool { n++; return n == 10 }) || n != 10 {
+		t.Fatalf("query: expected traversal to be interrupted after 10 elements")
+	}
+	if (*Tree)(nil).Query().Each(nil) {
+		t.Fatalf("query: unexpected interrupted traversal of nil tree")
+	}
+}
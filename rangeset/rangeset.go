@@ -0,0 +1,126 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rangeset implements an immutable set of integers stored as
+// disjoint intervals in an llrb tree. Adjacent and overlapping
+// intervals are merged on insertion and split on removal.
+package rangeset
+
+import "github.com/mars9/llrb"
+
+// Interval is the half-open interval [Lo, Hi).
+type Interval struct {
+	Lo, Hi int64
+}
+
+// Compare implements the llrb.Element interface, ordering intervals by
+// their lower bound. Intervals in a Set are disjoint, so the lower bound
+// identifies them.
+func (iv Interval) Compare(elem llrb.Element) int {
+	o := elem.(Interval)
+	switch {
+	case iv.Lo < o.Lo:
+		return -1
+	case iv.Lo > o.Lo:
+		return 1
+	}
+	return 0
+}
+
+// Set is an immutable set of integers. The zero Set is empty. Add and
+// Remove return a new Set sharing structure with the receiver.
+type Set struct {
+	tree *llrb.Tree
+}
+
+// Add returns the set with the interval [lo, hi) added. Intervals that
+// overlap or touch [lo, hi) are merged with it.
+func (s Set) Add(lo, hi int64) Set {
+	if lo >= hi {
+		return s
+	}
+	txn := s.tree.Txn()
+	if f, ok := s.tree.Floor(Interval{Lo: lo}).(Interval); ok && f.Hi >= lo {
+		txn.Delete(f)
+		lo = f.Lo
+		if f.Hi > hi {
+			hi = f.Hi
+		}
+	}
+	s.tree.Query().From(Interval{Lo: lo}).Each(func(elem llrb.Element) bool {
+		iv := elem.(Interval)
+		if iv.Lo > hi {
+			return true
+		}
+		txn.Delete(iv)
+		if iv.Hi > hi {
+			hi = iv.Hi
+		}
+		return false
+	})
+	txn.Insert(Interval{Lo: lo, Hi: hi})
+	return Set{tree: txn.Commit()}
+}
+
+// Remove returns the set with the interval [lo, hi) removed. Intervals
+// partially covered by [lo, hi) are split.
+func (s Set) Remove(lo, hi int64) Set {
+	if lo >= hi || s.tree.Len() == 0 {
+		return s
+	}
+	txn := s.tree.Txn()
+	if f, ok := s.tree.Floor(Interval{Lo: lo}).(Interval); ok && f.Lo < lo && f.Hi > lo {
+		txn.Insert(Interval{Lo: f.Lo, Hi: lo})
+		if f.Hi > hi {
+			txn.Insert(Interval{Lo: hi, Hi: f.Hi})
+		}
+	}
+	s.tree.Query().From(Interval{Lo: lo}).To(Interval{Lo: hi}).Each(func(elem llrb.Element) bool {
+		iv := elem.(Interval)
+		txn.Delete(iv)
+		if iv.Hi > hi {
+			txn.Insert(Interval{Lo: hi, Hi: iv.Hi})
+		}
+		return false
+	})
+	return Set{tree: txn.Commit()}
+}
+
+// Contains reports whether x is in the set.
+func (s Set) Contains(x int64) bool {
+	f, ok := s.tree.Floor(Interval{Lo: x}).(Interval)
+	return ok && x < f.Hi
+}
+
+// Intervals returns the disjoint intervals making up the set in
+// ascending order.
+func (s Set) Intervals() []Interval {
+	var ivs []Interval
+	s.tree.ForEach(func(elem llrb.Element) bool {
+		ivs = append(ivs, elem.(Interval))
+		return false
+	})
+	return ivs
+}
+
+// Gaps returns the intervals between the smallest and the largest
+// element of the set that are not in the set, in ascending order.
+func (s Set) Gaps() []Interval {
+	var (
+		gaps []Interval
+		prev *Interval
+	)
+	s.tree.ForEach(func(elem llrb.Element) bool {
+		iv := elem.(Interval)
+		if prev != nil {
+			gaps = append(gaps, Interval{Lo: prev.Hi, Hi: iv.Lo})
+		}
+		prev = &iv
+		return false
+	})
+	return gaps
+}
+
+// Len returns the number of disjoint intervals making up the set.
+func (s Set) Len() int { return s.tree.Len() }
@@ -0,0 +1,67 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rangeset
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	var s Set
+	s = s.Add(10, 20).Add(30, 40).Add(20, 25).Add(50, 60).Add(35, 55)
+	want := []Interval{{10, 25}, {30, 60}}
+	if ivs := s.Intervals(); !reflect.DeepEqual(ivs, want) {
+		t.Fatalf("set: expected %v, have %v", want, ivs)
+	}
+	if gaps := s.Gaps(); !reflect.DeepEqual(gaps, []Interval{{25, 30}}) {
+		t.Fatalf("set: expected gaps [{25 30}], have %v", gaps)
+	}
+
+	r := s.Remove(12, 14).Remove(40, 45).Remove(0, 11).Remove(55, 100)
+	want = []Interval{{11, 12}, {14, 25}, {30, 40}, {45, 55}}
+	if ivs := r.Intervals(); !reflect.DeepEqual(ivs, want) {
+		t.Fatalf("set: expected %v, have %v", want, ivs)
+	}
+	if s.Len() != 2 {
+		t.Fatalf("set: expected original set unchanged, have %v", s.Intervals())
+	}
+}
+
+func TestSetRandom(t *testing.T) {
+	var (
+		s    Set
+		bits [200]bool
+	)
+	for i := 0; i < 1000; i++ {
+		lo := rand.Int63n(200)
+		hi := lo + rand.Int63n(20)
+		if hi > 200 {
+			hi = 200
+		}
+		add := rand.Intn(2) == 0
+		if add {
+			s = s.Add(lo, hi)
+		} else {
+			s = s.Remove(lo, hi)
+		}
+		for x := lo; x < hi; x++ {
+			bits[x] = add
+		}
+
+		for x := range bits {
+			if s.Contains(int64(x)) != bits[x] {
+				t.Fatalf("set: expected contains %d = %t", x, bits[x])
+			}
+		}
+		ivs := s.Intervals()
+		for j := 1; j < len(ivs); j++ {
+			if ivs[j-1].Hi >= ivs[j].Lo {
+				t.Fatalf("set: intervals not coalesced: %v", ivs)
+			}
+		}
+	}
+}
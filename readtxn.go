@@ -0,0 +1,48 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// ReadTxn is a read-only transaction on one version of a tree. Unlike
+// Txn it copies nothing when started: committed trees are never
+// modified, so the handle just pins the version it was started on, and
+// all reads through it observe that version however many versions are
+// committed later. A ReadTxn may be used by several goroutines at once.
+type ReadTxn struct {
+	tree *Tree
+}
+
+// ReadTxn starts a read-only transaction on the tree.
+func (t *Tree) ReadTxn() ReadTxn { return ReadTxn{tree: t} }
+
+// Get returns the first match of elem in the tree, as Tree.Get.
+func (r ReadTxn) Get(elem Element) Element { return r.tree.Get(elem) }
+
+// Min returns the minimum value stored in the tree, as Tree.Min.
+func (r ReadTxn) Min() Element { return r.tree.Min() }
+
+// Max returns the maximum value stored in the tree, as Tree.Max.
+func (r ReadTxn) Max() Element { return r.tree.Max() }
+
+// Floor returns the largest element not greater than elem, as
+// Tree.Floor.
+func (r ReadTxn) Floor(elem Element) Element { return r.tree.Floor(elem) }
+
+// Ceil returns the smallest element not less than elem, as Tree.Ceil.
+func (r ReadTxn) Ceil(elem Element) Element { return r.tree.Ceil(elem) }
+
+// Range performs fn on all values over the interval [from, to), as
+// Tree.Range.
+func (r ReadTxn) Range(from, to Element, fn Visitor) bool {
+	return r.tree.Range(from, to, fn)
+}
+
+// ForEach performs fn on all values, as Tree.ForEach.
+func (r ReadTxn) ForEach(fn Visitor) bool { return r.tree.ForEach(fn) }
+
+// Len returns the number of elements in the tree.
+func (r ReadTxn) Len() int { return r.tree.Len() }
+
+// Tree returns the version of the tree the transaction reads.
+func (r ReadTxn) Tree() *Tree { return r.tree }
@@ -0,0 +1,48 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadTxn(t *testing.T) {
+	tree := randomTree(100, 400)
+	want := elements(tree)
+	r := tree.ReadTxn()
+
+	txn := tree.Txn()
+	txn.DeleteMin()
+	txn.Insert(compInt(1000))
+	txn.Commit()
+
+	if r.Len() != len(want) || r.Min() != want[0] || r.Max() != want[len(want)-1] {
+		t.Fatalf("read txn: expected version it was started on")
+	}
+	if r.Get(want[0]) != want[0] || r.Get(compInt(1000)) != nil {
+		t.Fatalf("read txn: unexpected get results")
+	}
+	if r.Floor(compInt(1000)) != want[len(want)-1] || r.Ceil(compInt(-1)) != want[0] {
+		t.Fatalf("read txn: unexpected floor or ceil")
+	}
+	var have []Element
+	r.ForEach(func(elem Element) bool {
+		have = append(have, elem)
+		return false
+	})
+	if !reflect.DeepEqual(want, have) {
+		t.Fatalf("read txn: expected values %v, have %v", want, have)
+	}
+	var n int
+	r.Range(compInt(0), compInt(400), func(Element) bool { n++; return false })
+	if n != len(want) || r.Tree() != tree {
+		t.Fatalf("read txn: expected %d elements in range, have %d", len(want), n)
+	}
+
+	if r := (*Tree)(nil).ReadTxn(); r.Len() != 0 || r.Get(compInt(1)) != nil {
+		t.Fatalf("read txn: expected nil tree to behave like an empty tree")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Records orders values of a struct type T by the fields carrying an
+// llrb tag, saving hand-written Compare methods for record types. The
+// tag gives the priority of the field as a positive integer, fields
+// with lower priorities being compared first, optionally followed by
+// ",desc" for descending order:
+//
+//	type Event struct {
+//		Host string    `llrb:"1"`
+//		At   time.Time `llrb:"2,desc"`
+//		Msg  string
+//	}
+//
+// Tagged fields must be of a boolean, integer, floating-point or string
+// kind, or of type time.Time. Floats are compared with cmp.Compare, so
+// NaNs sort first. Values are stored in trees wrapped by Elem.
+type Records[T any] struct {
+	fields []recordField
+}
+
+type recordField struct {
+	index    int
+	priority int
+	desc     bool
+	compare  func(a, b reflect.Value) int
+}
+
+// NewRecords returns the Records for T, checking the tags of its fields
+// once. It returns an error if T is not a struct, if no field is
+// tagged, or if a tag is malformed, duplicates a priority or is on a
+// field of an unsupported type.
+func NewRecords[T any]() (*Records[T], error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("llrb: records of %v: not a struct", typ)
+	}
+	var fields []recordField
+	priority := make(map[int]string)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag, ok := f.Tag.Lookup("llrb")
+		if !ok || tag == "-" {
+			continue
+		}
+		p, opt, _ := strings.Cut(tag, ",")
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || (opt != "" && opt != "desc") {
+			return nil, fmt.Errorf("llrb: records of %v: field %s: malformed tag %q", typ, f.Name, tag)
+		}
+		if other, dup := priority[n]; dup {
+			return nil, fmt.Errorf("llrb: records of %v: fields %s and %s have priority %d", typ, other, f.Name, n)
+		}
+		priority[n] = f.Name
+		compare := recordCompare(f)
+		if compare == nil {
+			return nil, fmt.Errorf("llrb: records of %v: field %s: unsupported type %v", typ, f.Name, f.Type)
+		}
+		fields = append(fields, recordField{index: i, priority: n, desc: opt == "desc", compare: compare})
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("llrb: records of %v: no tagged fields", typ)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].priority < fields[j].priority })
+	return &Records[T]{fields: fields}, nil
+}
+
+// recordCompare returns the comparison of values of the field f, or nil
+// if its type is not supported.
+func recordCompare(f reflect.StructField) func(a, b reflect.Value) int {
+	if f.Type == reflect.TypeFor[time.Time]() {
+		if !f.IsExported() {
+			return nil
+		}
+		return func(a, b reflect.Value) int {
+			return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+		}
+	}
+	switch f.Type.Kind() {
+	case reflect.Bool:
+		return func(a, b reflect.Value) int {
+			switch x, y := a.Bool(), b.Bool(); {
+			case x == y:
+				return 0
+			case y:
+				return -1
+			}
+			return 1
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Int(), b.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Uint(), b.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Float(), b.Float()) }
+	case reflect.String:
+		return func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) }
+	}
+	return nil
+}
+
+// Compare compares a and b by their tagged fields.
+func (r *Records[T]) Compare(a, b T) int {
+	va, vb := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	for _, f := range r.fields {
+		if c := f.compare(va.Field(f.index), vb.Field(f.index)); c != 0 {
+			if f.desc {
+				return -c
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+// Elem returns v as an Element ordered by r.
+func (r *Records[T]) Elem(v T) Record[T] {
+	return Record[T]{V: v, r: r}
+}
+
+// Record is a value of a struct type stored in a tree, ordered by the
+// Records that made it.
+type Record[T any] struct {
+	V T
+	r *Records[T]
+}
+
+// Compare implements the Element interface.
+func (x Record[T]) Compare(elem Element) int {
+	return x.r.Compare(x.V, elem.(Record[T]).V)
+}
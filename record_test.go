@@ -0,0 +1,76 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type logEntry struct {
+	At   time.Time `llrb:"2,desc"`
+	Host string    `llrb:"1"`
+	Msg  string
+	id   uint16 `llrb:"3"`
+}
+
+func TestRecords(t *testing.T) {
+	r, err := NewRecords[logEntry]()
+	if err != nil {
+		t.Fatalf("records: unexpected error: %v", err)
+	}
+	t0 := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []logEntry{
+		{Host: "b", At: t0, id: 1},
+		{Host: "a", At: t0, id: 2},
+		{Host: "a", At: t0.Add(time.Hour), id: 1},
+		{Host: "a", At: t0, id: 1, Msg: "replaced"},
+		{Host: "a", At: t0, id: 1, Msg: "replacement"},
+	}
+	txn := New().Txn()
+	for _, e := range entries {
+		txn.Insert(r.Elem(e))
+	}
+	var have []logEntry
+	txn.Commit().ForEach(func(elem Element) bool {
+		have = append(have, elem.(Record[logEntry]).V)
+		return false
+	})
+	want := []logEntry{entries[2], entries[4], entries[1], entries[0]}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("records: expected %v, have %v", want, have)
+	}
+}
+
+func TestRecordsInvalid(t *testing.T) {
+	type noTags struct{ A int }
+	type badTag struct {
+		A int `llrb:"first"`
+	}
+	type badOption struct {
+		A int `llrb:"1,asc"`
+	}
+	type duplicate struct {
+		A int `llrb:"1"`
+		B int `llrb:"1"`
+	}
+	type unsupported struct {
+		A []int `llrb:"1"`
+	}
+	for name, fn := range map[string]func() error{
+		"not struct":  func() error { _, err := NewRecords[int](); return err },
+		"no tags":     func() error { _, err := NewRecords[noTags](); return err },
+		"bad tag":     func() error { _, err := NewRecords[badTag](); return err },
+		"bad option":  func() error { _, err := NewRecords[badOption](); return err },
+		"duplicate":   func() error { _, err := NewRecords[duplicate](); return err },
+		"unsupported": func() error { _, err := NewRecords[unsupported](); return err },
+	} {
+		if err := fn(); err == nil || !strings.HasPrefix(err.Error(), "llrb: records of ") {
+			t.Fatalf("records %s: expected error, have %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// ReplaceRange deletes all elements over the interval [from, to) and
+// inserts elems in their place, refreshing a partition of the tree in
+// one step. elems must be in ascending order, without elements that
+// compare equal, and lie within [from, to). Rather than deleting and
+// inserting elements one by one, the tree is split at from and to, the
+// replacements are built into a balanced tree and the three parts are
+// joined, which takes time logarithmic in the size of the tree plus the
+// time to build the replacements. A transaction tracking changes also
+// records every deleted element. The replacement counts as a single
+// operation against the limits of the transaction. ReplaceRange panics
+// if to is less than from or if elems violate these conditions, leaving
+// the transaction unchanged.
+func (t *Txn) ReplaceRange(from, to Element, elems []Element) {
+	t.enter()
+	defer t.leave()
+
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	for i, elem := range elems {
+		if elem.Compare(from) < 0 || elem.Compare(to) >= 0 {
+			panic("replacement outside range")
+		}
+		if i > 0 && elem.Compare(elems[i-1]) <= 0 {
+			panic("replacements not in ascending order")
+		}
+	}
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+
+	p := t.probe()
+	if p != nil {
+		// Splitting and joining copy the nodes on the paths to from
+		// and to, and building adds a node per replacement.
+		p.depth = 4*t.tree.root.blackHeight() + len(elems)
+	}
+	l, r := t.tree.root.split(func(elem Element) bool { return elem.Compare(from) < 0 })
+	old, r := r.split(func(elem Element) bool { return elem.Compare(to) < 0 })
+	if t.track && old != nil {
+		old.do(func(elem Element) bool {
+			t.record(ChangeDelete, elem)
+			return false
+		})
+	}
+	for _, elem := range elems {
+		t.bloom.insert(elem)
+		t.record(ChangeInsert, elem)
+	}
+	m := -old.len()
+	t.account(opInsert, p)
+	t.bloom.delete(m)
+	t.tree.deletes -= m
+	t.tree.size += m + len(elems)
+	t.tree.root = join2(join2(l, build(elems, t.tree.aug)), r)
+}
@@ -0,0 +1,98 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestReplaceRange(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		base := randomTree(rng.Intn(300), 1000)
+		from := compInt(rng.Intn(1000))
+		to := from + compInt(rng.Intn(200))
+		var elems []Element
+		for v := from; v < to; v += compInt(rng.Intn(10) + 1) {
+			elems = append(elems, v)
+		}
+
+		var want []Element
+		for _, e := range elements(base) {
+			if e.(compInt) < from {
+				want = append(want, e)
+			}
+		}
+		want = append(want, elems...)
+		for _, e := range elements(base) {
+			if e.(compInt) >= to {
+				want = append(want, e)
+			}
+		}
+
+		txn := base.Txn()
+		txn.TrackChanges()
+		txn.ReplaceRange(from, to, elems)
+		deletes, inserts := 0, 0
+		for _, c := range txn.Changes() {
+			if c.Op == ChangeDelete {
+				deletes++
+			} else {
+				inserts++
+			}
+		}
+		tree := txn.Commit()
+		if err := tree.Check(); err != nil {
+			t.Fatalf("replace range: %v", err)
+		}
+		if have := elements(tree); len(have)+len(want) > 0 && !reflect.DeepEqual(have, want) {
+			t.Fatalf("replace range [%v, %v): expected %v, have %v", from, to, want, have)
+		}
+		if tree.Len() != len(want) || inserts != len(elems) || deletes != base.Len()+len(elems)-len(want) {
+			t.Fatalf("replace range: wrong length %d or changes %d/%d", tree.Len(), deletes, inserts)
+		}
+	}
+}
+
+func TestReplaceRangeInvalid(t *testing.T) {
+	for name, elems := range map[string][]Element{
+		"below":      {compInt(5), compInt(15)},
+		"above":      {compInt(10), compInt(20)},
+		"unordered":  {compInt(12), compInt(11)},
+		"duplicates": {compInt(12), compInt(12)},
+	} {
+		txn := randomTree(50, 100).Txn()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("replace range %s: expected panic", name)
+				}
+			}()
+			txn.ReplaceRange(compInt(10), compInt(20), elems)
+		}()
+		if len(txn.changes) != 0 {
+			t.Fatalf("replace range %s: transaction changed", name)
+		}
+	}
+}
+
+func TestReplaceRangeLimits(t *testing.T) {
+	txn := randomTree(100, 1000).Txn()
+	txn.SetLimits(TxnLimits{Nodes: 50})
+	txn.ReplaceRange(compInt(100), compInt(200), []Element{compInt(150)})
+	if txn.ops != 1 || txn.copied == 0 {
+		t.Fatalf("replace range: expected one operation copying nodes, have %d copying %d", txn.ops, txn.copied)
+	}
+	elems := make([]Element, 60)
+	for i := range elems {
+		elems[i] = compInt(300 + i)
+	}
+	txn.ReplaceRange(compInt(300), compInt(400), elems)
+	if err := txn.TryInsert(compInt(1)); err != ErrTxnLimit {
+		t.Fatalf("replace range: expected %v, have %v", ErrTxnLimit, err)
+	}
+}
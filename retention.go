@@ -0,0 +1,91 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"sync"
+	"time"
+)
+
+// Retention keeps a bounded history of committed tree versions, so
+// long-running services can offer recent snapshots without leaking all
+// history. Versions are dropped once they are older than the newest
+// maxVersions or than maxAge; the newest version is always kept. It is
+// safe for concurrent use.
+type Retention struct {
+	maxVersions int
+	maxAge      time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	versions []version // oldest first
+}
+
+type version struct {
+	tree *Tree
+	at   time.Time
+}
+
+// NewRetention returns a Retention keeping at most maxVersions versions
+// no older than maxAge. A zero limit is not enforced.
+func NewRetention(maxVersions int, maxAge time.Duration) *Retention {
+	return &Retention{maxVersions: maxVersions, maxAge: maxAge, now: time.Now}
+}
+
+// Add records t as the newest version and drops expired versions.
+func (r *Retention) Add(t *Tree) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions = append(r.versions, version{tree: t, at: r.now()})
+	r.prune()
+}
+
+// Store publishes t to a and records it as the newest version.
+func (r *Retention) Store(a *Atomic, t *Tree) {
+	a.Store(t)
+	r.Add(t)
+}
+
+// Versions drops expired versions and returns the retained ones,
+// oldest first.
+func (r *Retention) Versions() []*Tree {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune()
+	trees := make([]*Tree, len(r.versions))
+	for i, v := range r.versions {
+		trees[i] = v.tree
+	}
+	return trees
+}
+
+// LiveNodes returns the number of distinct nodes held by the retained
+// versions.
+func (r *Retention) LiveNodes() int { return LiveNodes(r.Versions()...) }
+
+// LiveBytes returns the number of bytes occupied by the distinct nodes
+// held by the retained versions.
+func (r *Retention) LiveBytes() int { return LiveBytes(r.Versions()...) }
+
+func (r *Retention) prune() {
+	n := 0
+	if r.maxVersions > 0 && len(r.versions) > r.maxVersions {
+		n = len(r.versions) - r.maxVersions
+	}
+	if r.maxAge > 0 {
+		now := r.now()
+		for n < len(r.versions)-1 && now.Sub(r.versions[n].at) > r.maxAge {
+			n++
+		}
+	}
+	if n == 0 {
+		return
+	}
+	copy(r.versions, r.versions[n:])
+	for i := len(r.versions) - n; i < len(r.versions); i++ {
+		r.versions[i] = version{}
+	}
+	r.versions = r.versions[:len(r.versions)-n]
+}
@@ -0,0 +1,47 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetention(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := NewRetention(3, time.Minute)
+	r.now = func() time.Time { return now }
+
+	root := &Atomic{}
+	var trees []*Tree
+	for i := compInt(0); i < 5; i++ {
+		txn := root.Load().Txn()
+		txn.Insert(i)
+		tree := txn.Commit()
+		trees = append(trees, tree)
+		r.Store(root, tree)
+		now = now.Add(time.Second)
+	}
+	if root.Load() != trees[4] {
+		t.Fatalf("retention: expected newest version to be published")
+	}
+
+	versions := r.Versions()
+	if len(versions) != 3 || versions[0] != trees[2] || versions[2] != trees[4] {
+		t.Fatalf("retention: expected last 3 versions, have %d", len(versions))
+	}
+	if n := r.LiveNodes(); n != LiveNodes(trees[2:]...) {
+		t.Fatalf("retention: expected %d live nodes, have %d", LiveNodes(trees[2:]...), n)
+	}
+
+	now = now.Add(time.Minute - 2*time.Second)
+	if versions = r.Versions(); len(versions) != 2 {
+		t.Fatalf("retention: expected 2 versions younger than a minute, have %d", len(versions))
+	}
+	now = now.Add(time.Hour)
+	if versions = r.Versions(); len(versions) != 1 || versions[0] != trees[4] {
+		t.Fatalf("retention: expected only the newest version, have %d", len(versions))
+	}
+}
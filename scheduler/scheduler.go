@@ -0,0 +1,104 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scheduler implements a queue of timed events backed by an
+// immutable llrb tree. Pending events can be inspected through
+// snapshots while events are scheduled and popped concurrently.
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mars9/llrb"
+)
+
+// Item is an event scheduled for a point in time.
+type Item struct {
+	At      time.Time
+	Payload interface{}
+
+	seq uint64 // orders items scheduled for the same time
+}
+
+// Compare implements the llrb.Element interface, ordering items by
+// their time and items with the same time by the order in which they
+// were scheduled.
+func (it Item) Compare(elem llrb.Element) int {
+	o := elem.(Item)
+	if c := it.At.Compare(o.At); c != 0 {
+		return c
+	}
+	switch {
+	case it.seq < o.seq:
+		return -1
+	case it.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+// Time implements the llrb.Timed interface.
+func (it Item) Time() time.Time { return it.At }
+
+// Scheduler is a queue of items ordered by time. It is safe for
+// concurrent use.
+type Scheduler struct {
+	mu      sync.Mutex // serializes modifications
+	seq     uint64
+	pending llrb.Atomic
+}
+
+// New returns an empty Scheduler.
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Schedule adds an item with payload due at the given time.
+func (s *Scheduler) Schedule(at time.Time, payload interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	txn := s.pending.Load().Txn()
+	txn.Insert(Item{At: at, Payload: payload, seq: s.seq})
+	s.pending.Store(txn.Commit())
+}
+
+// PopDue removes and returns all items due at or before now, in the
+// order they are due.
+func (s *Scheduler) PopDue(now time.Time) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txn := s.pending.Load().Txn()
+	txn.TrackChanges()
+	txn.DeleteOlderThan(now.Add(time.Nanosecond))
+	changes := txn.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+	s.pending.Store(txn.Commit())
+
+	items := make([]Item, len(changes))
+	for i, c := range changes {
+		items[i] = c.Elem.(Item)
+	}
+	return items
+}
+
+// NextDeadline returns the time the earliest pending item is due. The
+// boolean is false if no items are pending.
+func (s *Scheduler) NextDeadline() (time.Time, bool) {
+	if min := s.pending.Load().Min(); min != nil {
+		return min.(Item).At, true
+	}
+	return time.Time{}, false
+}
+
+// Len returns the number of pending items.
+func (s *Scheduler) Len() int { return s.pending.Load().Len() }
+
+// Snapshot returns the set of pending items as a tree of Item elements.
+// The tree is immutable and unaffected by later calls to Schedule and
+// PopDue.
+func (s *Scheduler) Snapshot() *llrb.Tree { return s.pending.Load() }
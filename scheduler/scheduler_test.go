@@ -0,0 +1,47 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	s := New()
+	if _, ok := s.NextDeadline(); ok {
+		t.Fatalf("scheduler: unexpected deadline for empty scheduler")
+	}
+	if items := s.PopDue(time.Now()); items != nil {
+		t.Fatalf("scheduler: unexpected due items %v", items)
+	}
+
+	start := time.Unix(1000, 0)
+	s.Schedule(start.Add(3*time.Second), "c")
+	s.Schedule(start.Add(1*time.Second), "a")
+	s.Schedule(start.Add(2*time.Second), "b1")
+	s.Schedule(start.Add(2*time.Second), "b2")
+	s.Schedule(start.Add(4*time.Second), "d")
+
+	if d, ok := s.NextDeadline(); !ok || !d.Equal(start.Add(time.Second)) {
+		t.Fatalf("scheduler: expected deadline %v, got %v", start.Add(time.Second), d)
+	}
+
+	snap := s.Snapshot()
+	items := s.PopDue(start.Add(2 * time.Second))
+	var payloads []interface{}
+	for _, it := range items {
+		payloads = append(payloads, it.Payload)
+	}
+	if len(payloads) != 3 || payloads[0] != "a" || payloads[1] != "b1" || payloads[2] != "b2" {
+		t.Fatalf("scheduler: expected due items [a b1 b2], got %v", payloads)
+	}
+	if s.Len() != 2 || snap.Len() != 5 {
+		t.Fatalf("scheduler: expected 2 pending items and 5 in snapshot, have %d and %d", s.Len(), snap.Len())
+	}
+	if d, _ := s.NextDeadline(); !d.Equal(start.Add(3 * time.Second)) {
+		t.Fatalf("scheduler: expected deadline %v, got %v", start.Add(3*time.Second), d)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Sizer is implemented by elements that report the size of their
+// payload in bytes, for Bytes on trees created with WithSizes. Elements
+// that do not implement Sizer have size 0.
+type Sizer interface {
+	// Bytes returns the size of the element in bytes. Negative sizes
+	// count as 0. The size of a stored element must not change.
+	Bytes() int
+}
+
+// WithSizes makes every node keep the sum of the sizes in its subtree,
+// maintained through inserts and deletes like the subtree size, so the
+// total is available in constant time for size-capped eviction and
+// memory accounting. It costs a Sizer type assertion each time a node
+// is updated.
+func WithSizes() Option {
+	return func(t *Tree) {
+		t.aug |= augBytes
+	}
+}
+
+// sizeOf returns the size of elem in bytes.
+func sizeOf(elem Element) int {
+	if s, ok := elem.(Sizer); ok {
+		if v := s.Bytes(); v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// totalBytes returns the sum of the sizes of the elements in the
+// subtree rooted at n.
+func (n *node) totalBytes() int {
+	if n.kinds()&augBytes == 0 {
+		return 0
+	}
+	return n.aug.bytes
+}
+
+// Bytes returns the sum of the sizes of all Sizer elements in the tree,
+// or 0 if it was not created with WithSizes.
+func (t *Tree) Bytes() int {
+	if t == nil {
+		return 0
+	}
+	return t.root.totalBytes()
+}
+
+// Bytes returns the sum of the sizes of all Sizer elements in the tree,
+// or 0 if it was not created with WithSizes.
+func (t *Txn) Bytes() int {
+	t.enter()
+	defer t.leave()
+	return t.tree.Bytes()
+}
@@ -0,0 +1,46 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"strings"
+	"testing"
+)
+
+// blob is an element whose size is the length of its string.
+type blob string
+
+func (b blob) Compare(elem Element) int { return strings.Compare(string(b), string(elem.(blob))) }
+func (b blob) Bytes() int               { return len(b) }
+
+func TestBytes(t *testing.T) {
+	if n := (*Tree)(nil).Bytes(); n != 0 {
+		t.Fatalf("bytes: expected 0 for nil tree, have %d", n)
+	}
+
+	txn := New(WithSizes()).Txn()
+	want := 0
+	for i := 1; i <= 100; i++ {
+		txn.Insert(blob(strings.Repeat("x", i)))
+		want += i
+	}
+	txn.Insert(blob("xxx")) // replacement of equal size
+	txn.Delete(blob("xxxxx"))
+	txn.DeleteMax()
+	want -= 5 + 100
+	if n := txn.Bytes(); n != want {
+		t.Fatalf("bytes: expected %d in transaction, have %d", want, n)
+	}
+	tree := txn.Commit()
+	if n := tree.Bytes(); n != want {
+		t.Fatalf("bytes: expected %d, have %d", want, n)
+	}
+	if err := tree.Check(); err != nil {
+		t.Fatalf("bytes: %v", err)
+	}
+	if n := randomTree(100, 1000).Bytes(); n != 0 {
+		t.Fatalf("bytes: expected 0 for elements without size, have %d", n)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// A Codec converts elements to and from the bytes stored in snapshots.
+type Codec interface {
+	Marshal(Element) ([]byte, error)
+	Unmarshal([]byte) (Element, error)
+}
+
+// ErrSnapshot is returned by Restore if the snapshot is malformed.
+var ErrSnapshot = errors.New("llrb: malformed snapshot")
+
+// A snapshot consists of a header and a sequence of blocks:
+//
+//	header: magic "llrb", version byte, flags byte, uvarint element count
+//	block:  uvarint payload length, payload, CRC-32C of payload
+//
+// A block of length 0 ends the snapshot. A payload holds consecutive
+// elements in ascending order, each as a uvarint length followed by
+// the bytes produced by the Codec. If the flags have snapshotCompressed
+// set, each payload is compressed on its own by the Codec's Compressor,
+// and the checksum covers the compressed payload. Version 1 snapshots
+// have no checksums.
+const (
+	snapshotMagic     = "llrb"
+	snapshotVersion   = 2
+	snapshotBlockSize = 64 << 10 // payload size at which a block is flushed
+	snapshotMaxBlock  = 1 << 30  // largest payload accepted by Restore
+
+	snapshotCompressed = 1 << 0 // flag: payloads are compressed
+)
+
+// Persist writes all elements of the tree to w in the snapshot format,
+// encoding them with c. It matches the Persist method of a raft
+// FSMSnapshot, which receives the snapshot sink as an io.Writer. The
+// tree is immutable, so Persist can run concurrently with writers
+// producing new versions.
+func (t *Tree) Persist(w io.Writer, c Codec) error {
+	return writeSnapshot(w, c, t.Len(), t.ForEach)
+}
+
+// writeSnapshot writes the count elements passed by each to its visitor,
+// in ascending order, to w in the snapshot format.
+func writeSnapshot(w io.Writer, c Codec, count int, each func(Visitor) bool) error {
+	bw := bufio.NewWriter(w)
+	e := newSnapshotEncoder(c)
+	buf := e.header(nil, count)
+	var err error
+	each(func(elem Element) bool {
+		if _, err = bw.Write(buf); err != nil {
+			return true
+		}
+		buf, err = e.add(buf[:0], elem)
+		return err != nil
+	})
+	if err != nil {
+		return err
+	}
+	if buf, err = e.finish(buf); err != nil {
+		return err
+	}
+	if _, err := bw.Write(buf); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// snapshotEncoder encodes elements into the blocks of a snapshot.
+type snapshotEncoder struct {
+	c     Codec
+	comp  Compressor // nil if payloads are not compressed
+	block []byte     // payload of the current block
+	out   []byte     // buffer for compressed payloads
+}
+
+func newSnapshotEncoder(c Codec) *snapshotEncoder {
+	comp, _ := c.(Compressor)
+	return &snapshotEncoder{c: c, comp: comp}
+}
+
+// header appends the header of a snapshot of count elements to dst.
+func (e *snapshotEncoder) header(dst []byte, count int) []byte {
+	var flags byte
+	if e.comp != nil {
+		flags |= snapshotCompressed
+	}
+	dst = append(dst, snapshotMagic...)
+	dst = append(dst, snapshotVersion, flags)
+	return binary.AppendUvarint(dst, uint64(count))
+}
+
+// add adds elem to the current block and appends the block to dst once
+// it is full.
+func (e *snapshotEncoder) add(dst []byte, elem Element) ([]byte, error) {
+	b, err := e.c.Marshal(elem)
+	if err != nil {
+		return dst, err
+	}
+	e.block = binary.AppendUvarint(e.block, uint64(len(b)))
+	e.block = append(e.block, b...)
+	if len(e.block) >= snapshotBlockSize {
+		return e.flush(dst)
+	}
+	return dst, nil
+}
+
+// flush appends the current block, with its length and checksum, to
+// dst and starts a new one.
+func (e *snapshotEncoder) flush(dst []byte) ([]byte, error) {
+	payload := e.block
+	if e.comp != nil {
+		var err error
+		if e.out, err = e.comp.Compress(e.out[:0], e.block); err != nil {
+			return dst, err
+		}
+		payload = e.out
+	}
+	dst = binary.AppendUvarint(dst, uint64(len(payload)))
+	dst = append(dst, payload...)
+	dst = binary.BigEndian.AppendUint32(dst, crc32.Checksum(payload, crcTable))
+	e.block = e.block[:0]
+	return dst, nil
+}
+
+// finish appends the last block, if any, and the end of the snapshot to
+// dst.
+func (e *snapshotEncoder) finish(dst []byte) ([]byte, error) {
+	if len(e.block) > 0 {
+		var err error
+		if dst, err = e.flush(dst); err != nil {
+			return dst, err
+		}
+	}
+	return append(dst, 0), nil
+}
+
+// Restore reads a snapshot written by Persist from r, decoding elements
+// with c, and returns a balanced tree holding them configured by opts.
+// It matches the Restore method of a raft FSM, which receives the
+// snapshot as an io.ReadCloser; closing r is left to the caller.
+func Restore(r io.Reader, c Codec, opts ...Option) (*Tree, error) {
+	sr, err := newSnapshotReader(r, c)
+	if err != nil {
+		return nil, err
+	}
+	var elems []Element
+	if sr.count <= 1<<20 {
+		elems = make([]Element, 0, sr.count)
+	}
+	for {
+		elem, err := sr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+	return fromElements(elems, opts...), nil
+}
+
+// snapshotReader decodes the elements of a snapshot one at a time.
+type snapshotReader struct {
+	br    *bufio.Reader
+	c     Codec
+	comp  Compressor // nil if payloads are not compressed
+	crc   bool       // blocks carry checksums
+	count uint64     // number of elements in the snapshot
+	read  uint64     // number of elements returned by next
+
+	block  []byte // buffer for block payloads
+	plain  []byte // buffer for decompressed payloads
+	p      []byte // undecoded rest of the current block
+	prev   Element
+	blocks int   // number of blocks read
+	start  int64 // offset of the current block
+	offset int64 // number of bytes read
+}
+
+// newSnapshotReader reads the snapshot header from r.
+func newSnapshotReader(r io.Reader, c Codec) (*snapshotReader, error) {
+	br := bufio.NewReader(r)
+	hdr := make([]byte, len(snapshotMagic)+2)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, snapshotError(err)
+	}
+	if string(hdr[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrSnapshot)
+	}
+	version := hdr[len(snapshotMagic)]
+	if version < 1 || version > snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrSnapshot, version)
+	}
+	flags := hdr[len(snapshotMagic)+1]
+	if flags&^snapshotCompressed != 0 {
+		return nil, fmt.Errorf("%w: unsupported flags %#x", ErrSnapshot, flags)
+	}
+	var comp Compressor
+	if flags&snapshotCompressed != 0 {
+		var ok bool
+		if comp, ok = c.(Compressor); !ok {
+			return nil, fmt.Errorf("%w: compressed, but codec is not a Compressor", ErrSnapshot)
+		}
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+	off := int64(len(hdr) + uvarintLen(count))
+	return &snapshotReader{br: br, c: c, comp: comp, crc: version >= 2, count: count, offset: off}, nil
+}
+
+// next returns the next element of the snapshot, checking that the
+// elements are in ascending order, or io.EOF after the last one.
+func (s *snapshotReader) next() (Element, error) {
+	for len(s.p) == 0 {
+		s.start = s.offset
+		n, err := binary.ReadUvarint(s.br)
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+		s.offset += int64(uvarintLen(n))
+		if n == 0 {
+			if s.read != s.count {
+				return nil, fmt.Errorf("%w: expected %d elements, have %d", ErrSnapshot, s.count, s.read)
+			}
+			return nil, io.EOF
+		}
+		s.blocks++
+		if n > snapshotMaxBlock {
+			return nil, s.errorf("length %d too large", n)
+		}
+		if uint64(cap(s.block)) < n {
+			s.block = make([]byte, n)
+		}
+		s.p = s.block[:n]
+		if _, err := io.ReadFull(s.br, s.p); err != nil {
+			return nil, snapshotError(err)
+		}
+		s.offset += int64(n)
+		if s.crc {
+			var sum [4]byte
+			if _, err := io.ReadFull(s.br, sum[:]); err != nil {
+				return nil, snapshotError(err)
+			}
+			s.offset += int64(len(sum))
+			if binary.BigEndian.Uint32(sum[:]) != crc32.Checksum(s.p, crcTable) {
+				return nil, s.errorf("checksum mismatch")
+			}
+		}
+		if s.comp != nil {
+			if s.plain, err = s.comp.Decompress(s.plain[:0], s.p); err != nil {
+				return nil, s.errorf("decompress: %w", err)
+			}
+			if len(s.plain) > snapshotMaxBlock {
+				return nil, s.errorf("decompressed length %d too large", len(s.plain))
+			}
+			s.p = s.plain
+		}
+	}
+
+	n, k := binary.Uvarint(s.p)
+	if k <= 0 || n > uint64(len(s.p)-k) {
+		return nil, s.errorf("truncated element")
+	}
+	elem, err := s.c.Unmarshal(s.p[k : k+int(n)])
+	if err != nil {
+		return nil, s.errorf("element %d: %w", s.read, err)
+	}
+	if s.prev != nil && elem.Compare(s.prev) <= 0 {
+		return nil, s.errorf("element %d out of order", s.read)
+	}
+	s.p = s.p[k+int(n):]
+	s.prev = elem
+	s.read++
+	return elem, nil
+}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errorf returns an ErrSnapshot error locating the current block.
+func (s *snapshotReader) errorf(format string, args ...interface{}) error {
+	args = append([]interface{}{ErrSnapshot, s.blocks - 1, s.start}, args...)
+	return fmt.Errorf("%w: block %d at offset %d: "+format, args...)
+}
+
+// uvarintLen returns the number of bytes in the uvarint encoding of x.
+func uvarintLen(x uint64) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
+	}
+	return n
+}
+
+// snapshotError reports an unexpected end of the snapshot as
+// ErrSnapshot and passes other read errors through.
+func snapshotError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: unexpected end", ErrSnapshot)
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// intCodec encodes compInt elements as decimal strings.
+type intCodec struct{}
+
+func (intCodec) Marshal(elem Element) ([]byte, error) {
+	return strconv.AppendInt(nil, int64(elem.(compInt)), 10), nil
+}
+
+func (intCodec) Unmarshal(b []byte) (Element, error) {
+	i, err := strconv.Atoi(string(b))
+	return compInt(i), err
+}
+
+func TestPersistRestore(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 50000} {
+		tree := randomTree(n, 4*n+1)
+		var buf bytes.Buffer
+		if err := tree.Persist(&buf, intCodec{}); err != nil {
+			t.Fatalf("persist: %v", err)
+		}
+		restored, err := Restore(&buf, intCodec{}, WithStats())
+		if err != nil {
+			t.Fatalf("restore: %v", err)
+		}
+		checkTree(t, "restore", restored)
+		if restored.Len() != n || !reflect.DeepEqual(elements(tree), elements(restored)) {
+			t.Fatalf("restore: expected %d elements as persisted, have %d", n, restored.Len())
+		}
+		if restored.stats == nil {
+			t.Fatalf("restore: options not applied")
+		}
+	}
+}
+
+func TestRestoreMalformed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := randomTree(10, 100).Persist(&buf, intCodec{}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	good := buf.Bytes()
+
+	for name, b := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("LLRB"), good[4:]...),
+		"version":   append(append([]byte("llrb"), 9), good[5:]...),
+		"truncated": good[:len(good)-3],
+		"count":     append(append([]byte("llrb"), snapshotVersion, 0, 11), good[7:]...),
+		"checksum":  append(append([]byte(nil), good[:len(good)-6]...), good[len(good)-6]^1, 0, 0, 0, 0, 0),
+	} {
+		if _, err := Restore(bytes.NewReader(b), intCodec{}); !errors.Is(err, ErrSnapshot) {
+			t.Fatalf("restore %s: expected ErrSnapshot, have %v", name, err)
+		}
+	}
+}
+
+func TestRestoreVersion1(t *testing.T) {
+	// Version 1 snapshots have no block checksums.
+	v1 := []byte("llrb\x01\x00\x02\x04\x01\x35\x01\x37\x00")
+	tree, err := Restore(bytes.NewReader(v1), intCodec{})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if want := []Element{compInt(5), compInt(7)}; !reflect.DeepEqual(elements(tree), want) {
+		t.Fatalf("restore: expected %v, have %v", want, elements(tree))
+	}
+}
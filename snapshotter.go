@@ -0,0 +1,123 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SnapshotPolicy configures when a Snapshotter takes snapshots.
+type SnapshotPolicy struct {
+	Interval time.Duration // time between snapshots, 0 to disable
+	Commits  int           // commits between snapshots, 0 to disable
+	OnError  func(error)   // called with errors returned by the sink, may be nil
+}
+
+// A Snapshotter persists the tree held by an Atomic in the background,
+// on an interval, after a number of commits reported through Committed,
+// or both. Snapshots are taken one at a time by a single goroutine;
+// triggers arriving while a snapshot is being taken are coalesced into
+// one following snapshot, and versions already persisted are skipped.
+type Snapshotter struct {
+	root   *Atomic
+	sink   func(*Tree) error
+	policy SnapshotPolicy
+
+	commits atomic.Int64  // commits since the last snapshot
+	kick    chan struct{} // requests a snapshot, buffered
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	last *Tree // version last passed to sink, owned by loop
+	err  atomic.Pointer[error]
+}
+
+// NewSnapshotter returns a Snapshotter passing the tree held by root to
+// sink according to p. The sink typically calls Persist on a new file or
+// object. The Snapshotter runs until Close is called.
+func NewSnapshotter(root *Atomic, sink func(*Tree) error, p SnapshotPolicy) *Snapshotter {
+	s := &Snapshotter{
+		root:   root,
+		sink:   sink,
+		policy: p,
+		kick:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// Committed records that a new version of the tree has been published,
+// requesting a snapshot once the policy's number of commits is reached.
+func (s *Snapshotter) Committed() {
+	if n := s.policy.Commits; n > 0 && s.commits.Add(1) >= int64(n) {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Err returns the error of the last snapshot, or nil if it succeeded or
+// no snapshot has been taken.
+func (s *Snapshotter) Err() error {
+	if err := s.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Close stops the Snapshotter, waiting for a snapshot in progress, and
+// takes a final snapshot if the tree changed since the last one. It
+// returns the error of the last snapshot taken.
+func (s *Snapshotter) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return s.Err()
+}
+
+func (s *Snapshotter) loop() {
+	defer close(s.done)
+
+	var tick <-chan time.Time
+	if s.policy.Interval > 0 {
+		t := time.NewTicker(s.policy.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-tick:
+		case <-s.kick:
+		case <-s.stop:
+			s.snapshot()
+			return
+		}
+		s.snapshot()
+	}
+}
+
+// snapshot passes the current version of the tree to the sink unless it
+// has been persisted already.
+func (s *Snapshotter) snapshot() {
+	s.commits.Store(0)
+	tree := s.root.Load()
+	if tree == s.last {
+		return
+	}
+	err := s.sink(tree)
+	s.err.Store(&err)
+	if err != nil {
+		if s.policy.OnError != nil {
+			s.policy.OnError(err)
+		}
+		return
+	}
+	s.last = tree
+}
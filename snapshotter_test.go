@@ -0,0 +1,106 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotter(t *testing.T) {
+	root := NewAtomic(&Tree{})
+	var (
+		mu      sync.Mutex
+		taken   []*Tree
+		running bool
+	)
+	snapped := make(chan struct{}, 100)
+	sink := func(tree *Tree) error {
+		mu.Lock()
+		if running {
+			mu.Unlock()
+			t.Errorf("snapshotter: overlapping snapshots")
+			return nil
+		}
+		running = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		taken = append(taken, tree)
+		running = false
+		mu.Unlock()
+		snapped <- struct{}{}
+		return nil
+	}
+	s := NewSnapshotter(root, sink, SnapshotPolicy{Commits: 10})
+
+	commit := func(i int) {
+		txn := root.Load().Txn()
+		txn.Insert(compInt(i))
+		root.Store(txn.Commit())
+		s.Committed()
+	}
+	for i := 0; i < 9; i++ {
+		commit(i)
+	}
+	select {
+	case <-snapped:
+		t.Fatalf("snapshotter: snapshot before 10 commits")
+	case <-time.After(20 * time.Millisecond):
+	}
+	commit(9)
+	select {
+	case <-snapped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("snapshotter: no snapshot after 10 commits")
+	}
+	for i := 10; i < 100; i++ {
+		commit(i)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("snapshotter: unexpected error %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(taken) < 2 || taken[len(taken)-1] != root.Load() {
+		t.Fatalf("snapshotter: expected final snapshot of the current version, have %d snapshots", len(taken))
+	}
+	if taken[0].Len() < 10 {
+		t.Fatalf("snapshotter: first snapshot holds %d elements", taken[0].Len())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("snapshotter: unexpected error on second close %v", err)
+	}
+}
+
+func TestSnapshotterInterval(t *testing.T) {
+	root := NewAtomic(randomTree(10, 100))
+	fail := errors.New("sink failed")
+	errs := make(chan error, 100)
+	calls := make(chan *Tree, 100)
+	s := NewSnapshotter(root, func(tree *Tree) error {
+		calls <- tree
+		return fail
+	}, SnapshotPolicy{Interval: time.Millisecond, OnError: func(err error) { errs <- err }})
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-errs:
+			if err != fail {
+				t.Fatalf("snapshotter: unexpected error %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("snapshotter: no snapshot on interval")
+		}
+	}
+	if err := s.Close(); err != fail {
+		t.Fatalf("snapshotter: expected sink error from close, have %v", err)
+	}
+	if s.Err() != fail {
+		t.Fatalf("snapshotter: expected sink error, have %v", s.Err())
+	}
+}
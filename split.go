@@ -0,0 +1,197 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// blackHeight returns the number of black nodes on each path from n to
+// a leaf, counting n itself.
+func (n *node) blackHeight() int {
+	h := 0
+	for ; n != nil; n = n.left {
+		if !n.isRed() {
+			h++
+		}
+	}
+	return h
+}
+
+// blacken returns n colored black, copying n if it is red.
+func (n *node) blacken() *node {
+	if !n.isRed() {
+		return n
+	}
+	n = n.copy()
+	n.color = black
+	return n
+}
+
+// fixInsert restores the left-leaning invariants at n after a red link
+// has been added below it, as during insertion.
+func (n *node) fixInsert() *node {
+	if n.right.isRed() && !n.left.isRed() {
+		n = n.rotateLeft()
+	}
+	if n.left.isRed() && n.left.left.isRed() {
+		n = n.rotateRight()
+	}
+	if n.left.isRed() && n.right.isRed() {
+		n.flipColors()
+	}
+	return n
+}
+
+// join returns a tree holding the elements of l, elem and the elements
+// of r, where all elements of l are less than elem and all elements of
+// r greater. The root of the returned tree is black. Neither l nor r is
+// modified. New nodes keep the augmentations k.
+func join(l *node, elem Element, r *node, k augKinds) *node {
+	l, r = l.blacken(), r.blacken()
+	lh, rh := l.blackHeight(), r.blackHeight()
+
+	var root *node
+	switch {
+	case lh > rh:
+		root = l.joinRight(elem, r, lh, rh, k)
+	case lh < rh:
+		root = r.joinLeft(l, elem, rh, lh, k)
+	default:
+		root = alloc(k)
+		root.elem, root.left, root.right = elem, l, r
+		root.update()
+	}
+	root.color = black // root is a new node
+	return root
+}
+
+// joinRight attaches elem and r, of black height rh, to the right spine
+// of n, of black height nh, at the first node of black height rh. The
+// new node keeps the augmentations k.
+func (n *node) joinRight(elem Element, r *node, nh, rh int, k augKinds) *node {
+	if nh == rh {
+		m := alloc(k)
+		m.elem, m.left, m.right, m.color = elem, n, r, red
+		m.update()
+		return m
+	}
+	// The right spine of a left-leaning tree is black.
+	root := n.copy()
+	root.right = root.right.joinRight(elem, r, nh-1, rh, k)
+	root.update()
+	return root.fixInsert()
+}
+
+// joinLeft attaches l, of black height lh, and elem to the left spine of
+// n, of black height nh, at the first black node of black height lh,
+// like joinRight.
+func (n *node) joinLeft(l *node, elem Element, nh, lh int, k augKinds) *node {
+	if nh == lh && !n.isRed() {
+		m := alloc(k)
+		m.elem, m.left, m.right, m.color = elem, l, n, red
+		m.update()
+		return m
+	}
+	ch := nh
+	if !n.isRed() {
+		ch--
+	}
+	root := n.copy()
+	root.left = root.left.joinLeft(l, elem, ch, lh, k)
+	root.update()
+	return root.fixInsert()
+}
+
+// join2 returns a tree holding the elements of l and r, where all
+// elements of l are less than the elements of r.
+func join2(l, r *node) *node {
+	if r == nil {
+		return l.blacken()
+	}
+	min, k := r.min().elem, r.kinds()
+	r, _ = r.blacken().deleteMin(nil)
+	return join(l, min, r, k)
+}
+
+// split divides the elements of n into those for which below holds and
+// the remaining ones. below must hold for a prefix of the elements in
+// sort order. n is not modified and the roots of both trees are black.
+func (n *node) split(below func(Element) bool) (l, r *node) {
+	if n == nil {
+		return nil, nil
+	}
+	if below(n.elem) {
+		l, r = n.right.split(below)
+		return join(n.left, n.elem, l, n.kinds()), r
+	}
+	l, r = n.left.split(below)
+	return l, join(r, n.elem, n.right, n.kinds())
+}
+
+// splitAt divides the elements of n into the first i in sort order and
+// the remaining ones, like split.
+func (n *node) splitAt(i int) (l, r *node) {
+	if n == nil {
+		return nil, nil
+	}
+	if ll := n.left.len(); i > ll {
+		l, r = n.right.splitAt(i - ll - 1)
+		return join(n.left, n.elem, l, n.kinds()), r
+	}
+	l, r = n.left.splitAt(i)
+	return l, join(r, n.elem, n.right, n.kinds())
+}
+
+// Extract returns a standalone tree holding the elements of t over the
+// interval [from, to). It is cut out of t by splitting, so it shares
+// all nodes off the two split paths with t, and the cost is logarithmic
+// in the size of t. The new tree has the options of t. If to is less
+// than from Extract will panic.
+func (t *Tree) Extract(from, to Element) *Tree {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	if t == nil || t.root == nil {
+		return t.derive(nil)
+	}
+	_, r := t.root.split(func(elem Element) bool { return elem.Compare(from) < 0 })
+	mid, _ := r.split(func(elem Element) bool { return elem.Compare(to) < 0 })
+	return t.derive(mid)
+}
+
+// derive returns a tree with the options of t holding the elements of
+// root, which shares structure with t.
+func (t *Tree) derive(root *node) *Tree {
+	tree := &Tree{}
+	if t != nil {
+		*tree = *t
+	}
+	tree.root = root
+	tree.size = root.len()
+	tree.bloom = nil // rebuilt by the next commit
+	tree.deletes = 0
+	return tree
+}
+
+// Partition divides the tree into n trees holding consecutive ranges of
+// its elements in sort order, with sizes differing by at most one. The
+// boundaries are found through the subtree sizes, and the parts share
+// all nodes off the split paths with t. The parts have the options of
+// t. Partition returns nil if n is less than 1.
+func (t *Tree) Partition(n int) []*Tree {
+	if n < 1 {
+		return nil
+	}
+	parts := make([]*Tree, n)
+	size := t.Len()
+	var rest *node
+	if t != nil {
+		rest = t.root
+	}
+	for k := 0; k < n-1; k++ {
+		var part *node
+		part, rest = rest.splitAt((k+1)*size/n - k*size/n)
+		parts[k] = t.derive(part)
+	}
+	parts[n-1] = t.derive(rest)
+	return parts
+}
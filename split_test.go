@@ -0,0 +1,140 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func checkTree(t *testing.T, name string, tree *Tree) {
+	t.Helper()
+	if !tree.isBST() {
+		t.Fatalf("%s: tree is not a BST", name)
+	}
+	if !tree.isBalanced() {
+		t.Fatalf("%s: tree is not balanced", name)
+	}
+	if !tree.is23() {
+		t.Fatalf("%s: tree is not a 2-3 tree", name)
+	}
+	if !tree.isSized() {
+		t.Fatalf("%s: subtree sizes are inconsistent", name)
+	}
+}
+
+func randomTree(n, max int) *Tree {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for txn.Len() < n {
+		txn.Insert(compInt(rand.Intn(max)))
+	}
+	return txn.Commit()
+}
+
+func TestSplitJoin(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 10, 100, 500} {
+		tree := randomTree(n, 4*n+1)
+		want := elements(tree)
+		for k := compInt(-1); k <= compInt(4*n+1); k++ {
+			l, r := tree.root.split(func(elem Element) bool { return elem.Compare(k) < 0 })
+			lt, rt := &Tree{root: l, size: l.len()}, &Tree{root: r, size: r.len()}
+			checkTree(t, "split", lt)
+			checkTree(t, "split", rt)
+			if lt.Len()+rt.Len() != n {
+				t.Fatalf("split: expected %d elements, have %d+%d", n, lt.Len(), rt.Len())
+			}
+			if lt.Len() > 0 && lt.Max().Compare(k) >= 0 || rt.Len() > 0 && rt.Min().Compare(k) < 0 {
+				t.Fatalf("split: elements on wrong side of %v", k)
+			}
+
+			j := &Tree{root: join2(l, r), size: n}
+			checkTree(t, "join", j)
+			if n > 0 && !reflect.DeepEqual(want, elements(j)) {
+				t.Fatalf("join: expected values %v, have %v", want, elements(j))
+			}
+		}
+		if have := elements(tree); !reflect.DeepEqual(want, have) {
+			t.Fatalf("split: source tree modified")
+		}
+		checkTree(t, "split source", tree)
+	}
+}
+
+func TestJoinUnbalanced(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 100}, {100, 0}, {1, 1000}, {1000, 1}, {30, 700}, {700, 30}} {
+		l, r := &Tree{}, &Tree{}
+		ltxn, rtxn := l.Txn(), r.Txn()
+		for i := 0; i < sizes[0]; i++ {
+			ltxn.Insert(compInt(i))
+		}
+		for i := 0; i < sizes[1]; i++ {
+			rtxn.Insert(compInt(sizes[0] + 1 + i))
+		}
+		l, r = ltxn.Commit(), rtxn.Commit()
+		j := &Tree{root: join(l.root, compInt(sizes[0]), r.root, 0), size: sizes[0] + sizes[1] + 1}
+		checkTree(t, "join", j)
+		for i, elem := range elements(j) {
+			if elem != compInt(i) {
+				t.Fatalf("join: expected element %d, got %v", i, elem)
+			}
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tree := randomTree(500, 2000)
+	want := elements(tree)
+	for _, r := range [][2]compInt{{-10, -1}, {0, 2000}, {100, 100}, {100, 101}, {250, 1750}, {1999, 3000}} {
+		sub := tree.Extract(r[0], r[1])
+		checkTree(t, "extract", sub)
+		var in []Element
+		for _, e := range want {
+			if e.Compare(r[0]) >= 0 && e.Compare(r[1]) < 0 {
+				in = append(in, e)
+			}
+		}
+		if have := elements(sub); len(in) != len(have) || len(in) > 0 && !reflect.DeepEqual(in, have) {
+			t.Fatalf("extract: expected %v for %v, have %v", in, r, have)
+		}
+		if sub.Len() > 10 && LiveNodes(tree, sub) >= tree.Len()+sub.Len() {
+			t.Fatalf("extract: expected shared nodes for %v", r)
+		}
+	}
+	if !reflect.DeepEqual(want, elements(tree)) {
+		t.Fatalf("extract: source tree modified")
+	}
+	if sub := (*Tree)(nil).Extract(compInt(0), compInt(1)); sub.Len() != 0 {
+		t.Fatalf("extract: expected empty tree from nil tree")
+	}
+}
+
+func TestPartition(t *testing.T) {
+	if parts := (&Tree{}).Partition(0); parts != nil {
+		t.Fatalf("partition: expected no parts, have %v", parts)
+	}
+	for _, size := range []int{0, 1, 7, 100, 1001} {
+		tree := randomTree(size, 4*size+1)
+		want := elements(tree)
+		for _, n := range []int{1, 2, 3, 8, 1000} {
+			parts := tree.Partition(n)
+			if len(parts) != n {
+				t.Fatalf("partition: expected %d parts, have %d", n, len(parts))
+			}
+			var have []Element
+			for _, part := range parts {
+				checkTree(t, "partition", part)
+				if d := part.Len() - size/n; d < 0 || d > 1 {
+					t.Fatalf("partition: unbalanced part of %d elements for %d/%d", part.Len(), size, n)
+				}
+				have = append(have, elements(part)...)
+			}
+			if !reflect.DeepEqual(want, have) {
+				t.Fatalf("partition: expected %v, have %v", want, have)
+			}
+		}
+	}
+}
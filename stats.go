@@ -0,0 +1,105 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "sync/atomic"
+
+// Stats holds the statistics recorded by a tree created with WithStats.
+type Stats struct {
+	Get    OpStats
+	Insert OpStats
+	Delete OpStats
+}
+
+// OpStats holds the statistics recorded for one kind of operation.
+type OpStats struct {
+	// Count is the number of operations that descended the tree.
+	Count uint64
+
+	// Compares is the number of Compare calls made by all operations.
+	// Compares divided by Count is the average cost of an operation in
+	// comparisons.
+	Compares uint64
+
+	// Depth is a histogram of the number of nodes visited by each
+	// operation. Depth[d] counts the operations that visited d nodes,
+	// the last bucket also counts all deeper operations. Depths far
+	// beyond twice the binary logarithm of the tree size point to an
+	// inconsistent Compare implementation.
+	Depth [64]uint64
+}
+
+// WithStats records statistics for Get, Insert and Delete operations,
+// including the number of Compare calls each of them made.
+// The statistics are shared by all trees committed from the returned
+// tree and can be read concurrently with Stats.
+func WithStats() Option {
+	return func(t *Tree) {
+		t.stats = &stats{}
+	}
+}
+
+// Stats returns the statistics recorded by all versions of the tree. It
+// returns the zero Stats if the tree was not created with WithStats.
+func (t *Tree) Stats() Stats {
+	if t == nil || t.stats == nil {
+		return Stats{}
+	}
+	return Stats{
+		Get:    t.stats.ops[opGet].load(),
+		Insert: t.stats.ops[opInsert].load(),
+		Delete: t.stats.ops[opDelete].load(),
+	}
+}
+
+const (
+	opGet = iota
+	opInsert
+	opDelete
+)
+
+type stats struct {
+	ops [3]opStats
+}
+
+type opStats struct {
+	count    uint64
+	compares uint64
+	depth    [64]uint64
+}
+
+// probe returns a probe to record an operation, or nil if statistics
+// are disabled.
+func (s *stats) probe() *probe {
+	if s == nil {
+		return nil
+	}
+	return &probe{}
+}
+
+func (s *stats) record(op int, p *probe) {
+	if s == nil {
+		return
+	}
+	o := &s.ops[op]
+	atomic.AddUint64(&o.count, 1)
+	atomic.AddUint64(&o.compares, uint64(p.compares))
+	d := p.depth
+	if d >= len(o.depth) {
+		d = len(o.depth) - 1
+	}
+	atomic.AddUint64(&o.depth[d], 1)
+}
+
+func (o *opStats) load() OpStats {
+	s := OpStats{
+		Count:    atomic.LoadUint64(&o.count),
+		Compares: atomic.LoadUint64(&o.compares),
+	}
+	for i := range o.depth {
+		s.Depth[i] = atomic.LoadUint64(&o.depth[i])
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	if s := (&Tree{}).Stats(); s != (Stats{}) {
+		t.Fatalf("stats: expected zero stats, got %+v", s)
+	}
+
+	tree := New(WithStats())
+	txn := tree.Txn()
+	for i := compInt(0); i < 1023; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+
+	// A complete tree of 1023 elements is 10 levels deep.
+	compact := tree.Compact()
+	for i := compInt(0); i < 1023; i++ {
+		compact.Get(i)
+	}
+	txn = compact.Txn()
+	txn.Delete(compInt(0))
+	txn.Commit()
+
+	s := tree.Stats()
+	if s.Insert.Count != 1023 || s.Get.Count != 1023 || s.Delete.Count != 1 {
+		t.Fatalf("stats: unexpected operation counts %d/%d/%d",
+			s.Get.Count, s.Insert.Count, s.Delete.Count)
+	}
+	var sum, visits uint64
+	for d, n := range s.Get.Depth {
+		if d > 10 && n != 0 {
+			t.Fatalf("stats: unexpected get depth %d", d)
+		}
+		sum += n
+		visits += uint64(d) * n
+	}
+	if sum != s.Get.Count || s.Get.Depth[10] != 512 {
+		t.Fatalf("stats: unexpected get depth histogram %v", s.Get.Depth)
+	}
+	if s.Get.Compares != visits || s.Insert.Compares == 0 {
+		t.Fatalf("stats: unexpected compare counts %d/%d", s.Get.Compares, s.Insert.Compares)
+	}
+	if s.Delete.Compares < 10 {
+		t.Fatalf("stats: expected at least 10 delete comparisons, have %d", s.Delete.Compares)
+	}
+	if s.Delete.Depth[10] != 1 {
+		t.Fatalf("stats: unexpected delete depth histogram %v", s.Delete.Depth)
+	}
+}
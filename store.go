@@ -0,0 +1,150 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned by a SnapshotStore for unknown snapshots.
+var ErrNotFound = errors.New("llrb: snapshot not found")
+
+// A SnapshotStore keeps named snapshots. Implementations may target a
+// file system, an object store or a database; DirStore is the file
+// system implementation.
+type SnapshotStore interface {
+	// Put stores the contents of r under name, replacing a snapshot of
+	// the same name. A failed Put must not leave a partial snapshot.
+	Put(name string, r io.Reader) error
+
+	// Get returns a reader for the snapshot stored under name, or an
+	// error wrapping ErrNotFound.
+	Get(name string) (io.ReadCloser, error)
+
+	// List returns the names of the stored snapshots in sorted order.
+	List() ([]string, error)
+
+	// Delete removes the snapshot stored under name, or returns an
+	// error wrapping ErrNotFound.
+	Delete(name string) error
+}
+
+// SaveSnapshot stores the elements of t, encoded with c, in s under
+// name, streaming them with Reader.
+func SaveSnapshot(s SnapshotStore, name string, t *Tree, c Codec) error {
+	return s.Put(name, t.Reader(c))
+}
+
+// LoadSnapshot restores the tree stored in s under name, decoding
+// elements with c, and configures it by opts.
+func LoadSnapshot(s SnapshotStore, name string, c Codec, opts ...Option) (*Tree, error) {
+	r, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return Restore(r, c, opts...)
+}
+
+// DirStore is a SnapshotStore keeping each snapshot in a file of a
+// directory. Snapshots are written to a temporary file that is synced
+// and renamed into place, so readers never see partial snapshots.
+type DirStore struct {
+	dir string
+}
+
+// tmpPrefix starts the names of temporary files written by DirStore.
+const tmpPrefix = ".tmp-"
+
+// NewDirStore returns a DirStore keeping snapshots in dir, which must
+// exist.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// path returns the path of the file for the snapshot name.
+func (d *DirStore) path(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("llrb: invalid snapshot name %q", name)
+	}
+	return filepath.Join(d.dir, name), nil
+}
+
+// Put implements the SnapshotStore interface.
+func (d *DirStore) Put(name string, r io.Reader) (err error) {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(d.dir, tmpPrefix+name+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err = io.Copy(f, r); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get implements the SnapshotStore interface.
+func (d *DirStore) Get(name string) (io.ReadCloser, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return f, err
+}
+
+// List implements the SnapshotStore interface.
+func (d *DirStore) List() ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete implements the SnapshotStore interface.
+func (d *DirStore) Delete(name string) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var _ SnapshotStore = (*DirStore)(nil)
+
+func TestDirStore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDirStore(dir)
+	if names, err := s.List(); err != nil || len(names) != 0 {
+		t.Fatalf("list: expected no snapshots, have %v (%v)", names, err)
+	}
+
+	a, b := randomTree(100, 1000), randomTree(1000, 5000)
+	for name, tree := range map[string]*Tree{"b": b, "a": a} {
+		if err := SaveSnapshot(s, name, tree, intCodec{}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	if err := s.Put("c", failReader{}); err == nil {
+		t.Fatalf("put: expected read error")
+	}
+	if names, err := s.List(); err != nil || !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Fatalf("list: expected [a b], have %v (%v)", names, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Fatalf("put: expected temporary files to be removed, have %d files", len(entries))
+	}
+
+	got, err := LoadSnapshot(s, "b", intCodec{})
+	if err != nil || !reflect.DeepEqual(elements(b), elements(got)) {
+		t.Fatalf("load: expected restored tree, have error %v", err)
+	}
+	if err := SaveSnapshot(s, "b", a, intCodec{}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got, err = LoadSnapshot(s, "b", intCodec{}); err != nil || got.Len() != a.Len() {
+		t.Fatalf("load: expected replaced snapshot, have error %v", err)
+	}
+
+	if err := s.Delete("a"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Delete("a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("delete: expected ErrNotFound, have %v", err)
+	}
+	if _, err := s.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get: expected ErrNotFound, have %v", err)
+	}
+	for _, name := range []string{"", ".hidden", "x/y", "../z"} {
+		if err := s.Put(name, failReader{}); err == nil {
+			t.Fatalf("put: expected error for name %q", name)
+		}
+	}
+}
+
+// failReader fails every read.
+type failReader struct{}
+
+func (failReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
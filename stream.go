@@ -0,0 +1,76 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "io"
+
+// Reader returns a reader streaming the elements of the tree in the
+// snapshot format written by Persist, encoded with c. Elements are
+// encoded as the reader is drained, a block at a time, so the snapshot
+// can be copied into an HTTP response, a compressor or a file without
+// building it in memory first. The reader keeps returning the elements
+// of the version of the tree it was created from.
+func (t *Tree) Reader(c Codec) io.Reader {
+	return t.newStream(nil, nil, t.Len(), c)
+}
+
+// RangeReader is like Reader but streams the elements of the tree over
+// the interval [from, to). If to is less than from RangeReader will
+// panic.
+func (t *Tree) RangeReader(from, to Element, c Codec) io.Reader {
+	if from.Compare(to) > 0 {
+		panic("inverted range")
+	}
+	return t.newStream(from, to, t.Rank(to)-t.Rank(from), c)
+}
+
+func (t *Tree) newStream(from, to Element, count int, c Codec) *snapshotStream {
+	s := &snapshotStream{enc: newSnapshotEncoder(c), cur: t.Cursor(), to: to}
+	s.cur.Seek(from)
+	s.buf = s.enc.header(nil, count)
+	return s
+}
+
+// snapshotStream is an io.Reader encoding the elements of a cursor in
+// the snapshot format.
+type snapshotStream struct {
+	enc  *snapshotEncoder
+	cur  *Cursor
+	to   Element // end of the range, nil if open
+	buf  []byte  // encoded bytes not yet read
+	done bool    // buf holds the end of the snapshot
+	err  error
+}
+
+func (s *snapshotStream) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		switch {
+		case s.err != nil:
+			return 0, s.err
+		case s.done:
+			return 0, io.EOF
+		}
+		s.fill()
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// fill encodes elements until a block is complete or the cursor is
+// exhausted.
+func (s *snapshotStream) fill() {
+	buf := s.buf[:0]
+	for len(buf) == 0 && s.err == nil {
+		if !s.cur.more(s.to) {
+			buf, s.err = s.enc.finish(buf)
+			s.done = true
+			s.cur.Close()
+			break
+		}
+		buf, s.err = s.enc.add(buf, s.cur.Next())
+	}
+	s.buf = buf
+}
@@ -0,0 +1,49 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	for _, n := range []int{0, 1, 1000, 50000} {
+		tree := randomTree(n, 4*n+1)
+		var want bytes.Buffer
+		if err := tree.Persist(&want, intCodec{}); err != nil {
+			t.Fatalf("persist: %v", err)
+		}
+		have, err := io.ReadAll(iotest.OneByteReader(tree.Reader(intCodec{})))
+		if err != nil || !bytes.Equal(want.Bytes(), have) {
+			t.Fatalf("reader: stream of %d elements differs from Persist (%v)", n, err)
+		}
+
+		from, to := compInt(n), compInt(3*n)
+		sub, err := Restore(tree.RangeReader(from, to, intCodec{}), intCodec{})
+		if err != nil {
+			t.Fatalf("range reader: %v", err)
+		}
+		if want, have := elements(tree.Extract(from, to)), elements(sub); !reflect.DeepEqual(want, have) {
+			t.Fatalf("range reader: expected %v, have %v", want, have)
+		}
+	}
+
+	r := randomTree(10, 100).Reader(failCodec{})
+	if _, err := io.ReadAll(r); !errors.Is(err, errMarshal) {
+		t.Fatalf("reader: expected marshal error, have %v", err)
+	}
+}
+
+var errMarshal = errors.New("marshal failed")
+
+// failCodec fails to marshal any element.
+type failCodec struct{ intCodec }
+
+func (failCodec) Marshal(Element) ([]byte, error) { return nil, errMarshal }
@@ -0,0 +1,128 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package syncmap implements an ordered map with sync.Map-compatible
+// Load, Store, LoadOrStore, LoadAndDelete, Swap, Delete and Range
+// methods, backed by immutable llrb trees. Reads never block, and
+// every version of the map can be kept as a snapshot.
+package syncmap
+
+import (
+	"sync"
+
+	"github.com/mars9/llrb"
+)
+
+// Map is an ordered map safe for concurrent use, with the
+// sync.Map-compatible methods Load, Store, LoadOrStore, LoadAndDelete,
+// Swap, Delete and Range. Keys must implement llrb.Element and are
+// ordered by their Compare method; passing any other key panics. The
+// zero Map is empty and ready for use. A Map must not be copied after
+// first use.
+type Map struct {
+	mu    sync.Mutex // serializes modifications
+	elems llrb.Atomic
+}
+
+// Entry is a key and its value as stored in the tree.
+type Entry struct {
+	Key   llrb.Element
+	Value any
+}
+
+// Compare implements the llrb.Element interface, ordering entries by
+// their keys.
+func (e Entry) Compare(elem llrb.Element) int {
+	return e.Key.Compare(elem.(Entry).Key)
+}
+
+// query returns the entry matching key.
+func query(key any) Entry {
+	return Entry{Key: key.(llrb.Element)}
+}
+
+// Load returns the value stored for key, or nil if there is none. The
+// ok result reports whether a value was found.
+func (m *Map) Load(key any) (value any, ok bool) {
+	e, ok := m.elems.Load().Get(query(key)).(Entry)
+	return e.Value, ok
+}
+
+// Store sets the value for key.
+func (m *Map) Store(key, value any) {
+	m.Swap(key, value)
+}
+
+// Swap sets the value for key and returns the previous value, if any.
+// The loaded result reports whether the key was present.
+func (m *Map) Swap(key, value any) (previous any, loaded bool) {
+	e := Entry{Key: key.(llrb.Element), Value: value}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tree := m.elems.Load()
+	old, loaded := tree.Get(e).(Entry)
+	txn := tree.Txn()
+	txn.Insert(e)
+	m.elems.Store(txn.Commit())
+	return old.Value, loaded
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise,
+// it stores and returns value. The loaded result is true if the value
+// was loaded, false if stored.
+func (m *Map) LoadOrStore(key, value any) (actual any, loaded bool) {
+	e := Entry{Key: key.(llrb.Element), Value: value}
+	if old, ok := m.elems.Load().Get(e).(Entry); ok {
+		return old.Value, true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tree := m.elems.Load()
+	if old, ok := tree.Get(e).(Entry); ok {
+		return old.Value, true
+	}
+	txn := tree.Txn()
+	txn.Insert(e)
+	m.elems.Store(txn.Commit())
+	return value, false
+}
+
+// LoadAndDelete deletes the value for key, returning the previous
+// value if any. The loaded result reports whether the key was present.
+func (m *Map) LoadAndDelete(key any) (value any, loaded bool) {
+	q := query(key)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tree := m.elems.Load()
+	old, loaded := tree.Get(q).(Entry)
+	if !loaded {
+		return nil, false
+	}
+	txn := tree.Txn()
+	txn.Delete(q)
+	m.elems.Store(txn.Commit())
+	return old.Value, true
+}
+
+// Delete deletes the value for key.
+func (m *Map) Delete(key any) {
+	m.LoadAndDelete(key)
+}
+
+// Range calls f for each key and value in ascending key order. If f
+// returns false, Range stops the iteration. Unlike sync.Map, Range
+// visits a consistent snapshot of the map: modifications made during
+// the iteration are not seen.
+func (m *Map) Range(f func(key, value any) bool) {
+	m.elems.Load().ForEach(func(elem llrb.Element) bool {
+		e := elem.(Entry)
+		return !f(e.Key, e.Value)
+	})
+}
+
+// Snapshot returns the current version of the map as a tree of Entry
+// elements.
+func (m *Map) Snapshot() *llrb.Tree {
+	return m.elems.Load()
+}
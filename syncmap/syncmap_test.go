@@ -0,0 +1,83 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package syncmap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+type key int
+
+func (k key) Compare(elem llrb.Element) int { return int(k - elem.(key)) }
+
+func TestMap(t *testing.T) {
+	var m Map
+	if _, ok := m.Load(key(1)); ok {
+		t.Fatalf("load: unexpected value in empty map")
+	}
+	m.Store(key(2), "b")
+	m.Store(key(1), "a")
+	if v, ok := m.Load(key(2)); !ok || v != "b" {
+		t.Fatalf("load: expected b, have %v, %v", v, ok)
+	}
+	if v, loaded := m.LoadOrStore(key(2), "x"); !loaded || v != "b" {
+		t.Fatalf("load or store: expected loaded b, have %v, %v", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore(key(3), "c"); loaded || v != "c" {
+		t.Fatalf("load or store: expected stored c, have %v, %v", v, loaded)
+	}
+	if v, loaded := m.Swap(key(1), "A"); !loaded || v != "a" {
+		t.Fatalf("swap: expected previous a, have %v, %v", v, loaded)
+	}
+	snap := m.Snapshot()
+	if v, loaded := m.LoadAndDelete(key(3)); !loaded || v != "c" {
+		t.Fatalf("load and delete: expected c, have %v, %v", v, loaded)
+	}
+	m.Delete(key(9))
+
+	var keys []llrb.Element
+	m.Range(func(k, v any) bool {
+		keys = append(keys, k.(llrb.Element))
+		return true
+	})
+	if len(keys) != 2 || keys[0] != key(1) || keys[1] != key(2) {
+		t.Fatalf("range: expected [1 2], have %v", keys)
+	}
+	n := 0
+	m.Range(func(k, v any) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Fatalf("range: expected stop after 1 entry, have %d", n)
+	}
+	if snap.Len() != 3 {
+		t.Fatalf("snapshot: expected 3 entries, have %d", snap.Len())
+	}
+}
+
+func TestMapConcurrent(t *testing.T) {
+	var (
+		m  Map
+		wg sync.WaitGroup
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				m.LoadOrStore(key(i), g)
+				m.Load(key(i / 2))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if n := m.Snapshot().Len(); n != 200 {
+		t.Fatalf("concurrent: expected 200 entries, have %d", n)
+	}
+}
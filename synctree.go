@@ -0,0 +1,87 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "sync"
+
+// SyncTree is a mutable tree safe for concurrent use, guarded by a
+// read-write mutex. Each modification runs and commits a transaction of
+// its own, which is simpler to use than Atomic and a Txn but slower for
+// batches of changes. The zero SyncTree is an empty tree with no
+// options set.
+type SyncTree struct {
+	mu   sync.RWMutex
+	tree *Tree
+}
+
+// NewSyncTree returns an empty SyncTree configured by opts.
+func NewSyncTree(opts ...Option) *SyncTree {
+	return &SyncTree{tree: New(opts...)}
+}
+
+// Insert inserts elem into the tree, replacing an element it matches.
+func (t *SyncTree) Insert(elem Element) {
+	t.update(func(txn *Txn) { txn.Insert(elem) })
+}
+
+// Delete deletes the element matching elem from the tree.
+func (t *SyncTree) Delete(elem Element) {
+	t.update(func(txn *Txn) { txn.Delete(elem) })
+}
+
+// Update runs fn on a transaction and commits it, holding the write
+// lock throughout, so that several modifications are applied
+// atomically.
+func (t *SyncTree) Update(fn func(*Txn)) {
+	t.update(fn)
+}
+
+func (t *SyncTree) update(fn func(*Txn)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	txn := t.tree.Txn()
+	fn(txn)
+	t.tree = txn.Commit()
+}
+
+// Get returns the element matching elem, or nil if there is none.
+func (t *SyncTree) Get(elem Element) Element {
+	return t.Snapshot().Get(elem)
+}
+
+// Min returns the minimum element in the tree, or nil if it is empty.
+func (t *SyncTree) Min() Element {
+	return t.Snapshot().Min()
+}
+
+// Max returns the maximum element in the tree, or nil if it is empty.
+func (t *SyncTree) Max() Element {
+	return t.Snapshot().Max()
+}
+
+// Len returns the number of elements stored in the tree.
+func (t *SyncTree) Len() int {
+	return t.Snapshot().Len()
+}
+
+// Range performs fn on all elements over the interval [from, to), as
+// Tree.Range. It traverses the version of the tree current when it was
+// called, without holding the lock, so fn may modify t.
+func (t *SyncTree) Range(from, to Element, fn Visitor) bool {
+	return t.Snapshot().Range(from, to, fn)
+}
+
+// ForEach performs fn on all elements, as Tree.ForEach. Like Range it
+// traverses the version current when it was called.
+func (t *SyncTree) ForEach(fn Visitor) bool {
+	return t.Snapshot().ForEach(fn)
+}
+
+// Snapshot returns the current version of the tree.
+func (t *SyncTree) Snapshot() *Tree {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree
+}
@@ -0,0 +1,54 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncTree(t *testing.T) {
+	var tree SyncTree
+	if tree.Len() != 0 || tree.Min() != nil || tree.Get(compInt(1)) != nil {
+		t.Fatalf("sync tree: expected empty zero value")
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				tree.Insert(compInt(g*100 + i))
+				tree.Get(compInt(i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if n := tree.Len(); n != 400 {
+		t.Fatalf("sync tree: expected 400 elements, have %d", n)
+	}
+
+	tree.Update(func(txn *Txn) {
+		txn.DeleteMin()
+		txn.DeleteMax()
+	})
+	if min, max := tree.Min(), tree.Max(); min != compInt(1) || max != compInt(398) {
+		t.Fatalf("sync tree: expected min 1 and max 398, have %v and %v", min, max)
+	}
+
+	n := 0
+	tree.Range(compInt(10), compInt(20), func(elem Element) bool {
+		tree.Delete(elem) // modifications do not affect the traversal
+		n++
+		return false
+	})
+	if n != 10 || tree.Len() != 388 {
+		t.Fatalf("sync tree: expected 10 visits and 388 elements, have %d and %d", n, tree.Len())
+	}
+	if err := tree.Snapshot().Check(); err != nil {
+		t.Fatalf("sync tree: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textkey
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Normalizer maps a string to the form it is compared by. A Unicode
+// normalization form of golang.org/x/text/unicode/norm is adapted by
+// Normalizer(norm.NFC.String), and combined with case folding by
+//
+//	textkey.Normalizer(func(s string) string {
+//		return textkey.Fold(norm.NFC.String(s))
+//	})
+type Normalizer func(string) string
+
+// Key returns s as a Key ordered by its normalized form.
+func (n Normalizer) Key(s string) Key {
+	return Key{S: s, sortKey: n(s)}
+}
+
+// Folded returns s as a Key ordered by its case folded form, as made by
+// Fold. Folded keys compare equal exactly if strings.EqualFold reports
+// their strings equal.
+func Folded(s string) Key {
+	return Key{S: s, sortKey: Fold(s)}
+}
+
+// Fold returns the case folded form of s, mapping each rune to the
+// smallest rune equivalent to it under Unicode simple case folding.
+func Fold(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= utf8.RuneSelf || 'a' <= c && c <= 'z' {
+			return strings.Map(fold, s)
+		}
+	}
+	return s
+}
+
+// fold returns the smallest rune in the case folding orbit of r.
+func fold(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
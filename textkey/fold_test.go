@@ -0,0 +1,51 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textkey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+func TestFolded(t *testing.T) {
+	pairs := [][2]string{
+		{"Go", "gO"},
+		{"straße", "STRAßE"},
+		{"K", "K"}, // Kelvin sign
+		{"Σίσυφος", "ΣΊΣΥΦΟΣ"},
+		{"go", "ga"},
+		{"ǅ", "ǆ"},
+		{"", "a"},
+	}
+	for _, p := range pairs {
+		equal := Folded(p[0]).Compare(Folded(p[1])) == 0
+		if want := strings.EqualFold(p[0], p[1]); equal != want {
+			t.Fatalf("folded %q, %q: expected equal %v, have %v", p[0], p[1], want, equal)
+		}
+	}
+
+	txn := llrb.New().Txn()
+	txn.Insert(Folded("README"))
+	txn.Insert(Folded("Makefile"))
+	tree := txn.Commit()
+	if e, ok := tree.Get(Folded("readme")).(Key); !ok || e.S != "README" {
+		t.Fatalf("folded: expected README, have %v", e)
+	}
+	if s := Fold("ABC-def"); s != "ABC-DEF" {
+		t.Fatalf("fold: expected ABC-DEF, have %q", s)
+	}
+}
+
+func TestNormalizer(t *testing.T) {
+	trim := Normalizer(strings.TrimSpace)
+	if trim.Key(" a ").Compare(trim.Key("a")) != 0 {
+		t.Fatalf("normalizer: expected trimmed keys to compare equal")
+	}
+	if k := trim.Key(" a "); k.S != " a " || string(k.SortKey()) != "a" {
+		t.Fatalf("normalizer: unexpected key %q with sort key %q", k.S, k.SortKey())
+	}
+}
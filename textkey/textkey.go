@@ -0,0 +1,56 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package textkey provides elements for string-keyed trees ordered by
+// something other than the bytes of the strings, such as locale-aware
+// collation, case folding or Unicode normalization. Each Key carries a
+// sort key computed once when the Key is made, so comparisons during
+// tree operations stay cheap.
+package textkey
+
+import (
+	"strings"
+
+	"github.com/mars9/llrb"
+)
+
+// Key is a string with the sort key it is ordered by. Keys compare
+// equal if their sort keys do, whatever their strings. All keys stored
+// in a tree must be made the same way, for example by the same
+// Collator.
+type Key struct {
+	S       string // string as given
+	sortKey string
+}
+
+// Compare implements the llrb.Element interface, ordering keys by their
+// sort keys.
+func (k Key) Compare(elem llrb.Element) int {
+	return strings.Compare(k.sortKey, elem.(Key).sortKey)
+}
+
+// SortKey returns the sort key of k.
+func (k Key) SortKey() []byte { return []byte(k.sortKey) }
+
+// String returns the string of k.
+func (k Key) String() string { return k.S }
+
+// A Collator appends the collation sort key of s to dst and returns the
+// extended buffer. Sort keys are compared bytewise. A collator of
+// golang.org/x/text/collate is adapted by
+//
+//	var buf collate.Buffer
+//	key := textkey.Collator(func(dst []byte, s string) []byte {
+//		defer buf.Reset()
+//		return append(dst, c.KeyFromString(&buf, s)...)
+//	})
+//
+// where c is a *collate.Collator, for example collate.New(language.German).
+// The adapted Collator shares buf and is not safe for concurrent use.
+type Collator func(dst []byte, s string) []byte
+
+// Key returns s as a Key ordered by its collation sort key.
+func (c Collator) Key(s string) Key {
+	return Key{S: s, sortKey: string(c(nil, s))}
+}
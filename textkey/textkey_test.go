@@ -0,0 +1,43 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textkey
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+// phonebook is a toy collation ignoring case and sorting "ä" as "ae".
+var phonebook = Collator(func(dst []byte, s string) []byte {
+	s = strings.NewReplacer("ä", "ae", "Ä", "ae").Replace(strings.ToLower(s))
+	return append(dst, s...)
+})
+
+func TestCollator(t *testing.T) {
+	txn := llrb.New().Txn()
+	for _, s := range []string{"Zebra", "Ärger", "adler", "Affe", "AERGER"} {
+		txn.Insert(phonebook.Key(s))
+	}
+	tree := txn.Commit()
+
+	var have []string
+	tree.ForEach(func(elem llrb.Element) bool {
+		have = append(have, elem.(Key).S)
+		return false
+	})
+	want := []string{"adler", "AERGER", "Affe", "Zebra"} // "AERGER" replaced "Ärger"
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("collator: expected %q, have %q", want, have)
+	}
+	if e, ok := tree.Get(phonebook.Key("ZEBRA")).(Key); !ok || e.String() != "Zebra" {
+		t.Fatalf("collator: expected Zebra, have %v", e)
+	}
+	if k := phonebook.Key("Ärger").SortKey(); string(k) != "aerger" {
+		t.Fatalf("collator: expected sort key aerger, have %q", k)
+	}
+}
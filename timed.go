@@ -0,0 +1,53 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "time"
+
+// Timed is implemented by the elements of time-keyed trees, which are
+// ordered by the time they return.
+type Timed interface {
+	Time() time.Time
+}
+
+// DeleteOlderThan deletes all elements older than ts from a tree of
+// Timed elements. Rather than deleting elements one by one, the tree is
+// split at ts, which takes time logarithmic in the size of the tree. A
+// transaction tracking changes also records every deleted element,
+// which takes time linear in their number.
+func (t *Txn) DeleteOlderThan(ts time.Time) {
+	t.enter()
+	defer t.leave()
+
+	if t.tree.root == nil {
+		return
+	}
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	p := t.probe()
+	if p != nil {
+		// Splitting copies the nodes on the path to ts.
+		p.depth = 2 * t.tree.root.blackHeight()
+	}
+	old, rest := t.tree.root.split(func(elem Element) bool {
+		return elem.(Timed).Time().Before(ts)
+	})
+	if old == nil {
+		return
+	}
+	if t.track {
+		old.do(func(elem Element) bool {
+			t.record(ChangeDelete, elem)
+			return false
+		})
+	}
+	m := -old.len()
+	t.account(opDelete, p)
+	t.bloom.delete(m)
+	t.tree.deletes -= m
+	t.tree.size += m
+	t.tree.root = rest
+}
@@ -0,0 +1,66 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"testing"
+	"time"
+)
+
+type event time.Time
+
+func (e event) Time() time.Time { return time.Time(e) }
+
+func (e event) Compare(elem Element) int {
+	return time.Time(e).Compare(time.Time(elem.(event)))
+}
+
+func TestDeleteOlderThan(t *testing.T) {
+	start := time.Unix(1000, 0)
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := 0; i < 1000; i++ {
+		txn.Insert(event(start.Add(time.Duration(i) * time.Second)))
+	}
+	v1 := txn.Commit()
+
+	txn = v1.Txn()
+	txn.TrackChanges()
+	txn.DeleteOlderThan(start.Add(250 * time.Second))
+	if n := len(txn.Changes()); n != 250 {
+		t.Fatalf("delete older than: expected 250 changes, have %d", n)
+	}
+	v2 := txn.Commit()
+	checkTree(t, "delete older than", v2)
+	if v2.Len() != 750 || time.Time(v2.Min().(event)) != start.Add(250*time.Second) {
+		t.Fatalf("delete older than: unexpected tree of length %d starting at %v", v2.Len(), v2.Min())
+	}
+	if v1.Len() != 1000 || v1.Min() != event(start) {
+		t.Fatalf("delete older than: source version modified")
+	}
+
+	txn = v2.Txn()
+	txn.DeleteOlderThan(start)
+	if txn.Len() != 750 {
+		t.Fatalf("delete older than: expected no deletions, have length %d", txn.Len())
+	}
+	txn.DeleteOlderThan(start.Add(time.Hour))
+	if n := len(txn.Changes()); n != 0 {
+		t.Fatalf("delete older than: expected no changes without tracking, have %d", n)
+	}
+	if v3 := txn.Commit(); v3.Len() != 0 || v3.root != nil {
+		t.Fatalf("delete older than: expected empty tree, have length %d", v3.Len())
+	}
+
+	txn = v1.Txn()
+	txn.SetLimits(TxnLimits{Nodes: 10})
+	txn.DeleteOlderThan(start.Add(500 * time.Second))
+	if txn.ops != 1 || txn.copied == 0 {
+		t.Fatalf("delete older than: expected one operation copying nodes, have %d copying %d", txn.ops, txn.copied)
+	}
+	if err := txn.TryInsert(event(start)); err != ErrTxnLimit {
+		t.Fatalf("delete older than: expected %v, have %v", ErrTxnLimit, err)
+	}
+}
@@ -18,18 +18,89 @@
 // Immutability is achieved by branch copying.
 package llrb
 
+import "time"
+
 // Tree manages the root node of an left-Leaning Red-Black  tree. Public
-// methods are exposed through this type.
+// methods are exposed through this type. A nil *Tree behaves like an
+// empty tree.
 type Tree struct {
-	root *node
-	size int
+	root    *node
+	size    int
+	version uint64 // commits leading to the tree
+
+	bloomBits int    // bits per element, 0 if the filter is disabled
+	bloom     *bloom // nil while the filter is stale or disabled
+
+	compactRatio float64 // 0 if automatic compaction is disabled
+	deletes      int     // deletions since the tree was last built
+
+	consolidateRatio float64 // 0 if consolidation at Commit is disabled
+
+	stats *stats // shared by all versions, nil if disabled
+
+	checkTxn bool // detect transactions used by several goroutines
+
+	leakf func(format string, args ...interface{}) // reports leaked transactions, nil if disabled
+	audit bool                                     // check element order during traversals
+
+	strict bool // check invariants after every transaction operation
+
+	meta    *Tree            // metaEntry elements, if metaNow is set
+	metaNow func() time.Time // clock stamping metadata, nil if disabled
+
+	codec Codec // encodes elements for MarshalBinary, nil if not set
+
+	aug augKinds // augmentations kept in every node
+}
+
+// An Option configures a Tree created by New. Options are carried over
+// to all trees committed from it.
+type Option func(*Tree)
+
+// New returns an empty tree configured by opts. The zero Tree is an
+// empty tree with no options set.
+func New(opts ...Option) *Tree {
+	t := &Tree{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 // Txn is a transaction on the tree. This transaction is applied
 // atomically and returns a new tree when committed. A transaction is not
 // thread safe, and should only be used by a single goroutine.
 type Txn struct {
-	tree *Tree
+	tree     *Tree
+	base     *node // root when started or last committed
+	bloom    bloomTxn
+	check    *txnCheck
+	created  []byte    // creation stack if leaks are detected
+	verified *node     // root last checked if strict checks are enabled
+	track    bool      // record changes for Changes
+	changes  []Change  // recorded if track is set
+	appends  []Element // ascending elements not yet joined to tree
+	buffered []Element // elements not yet merged into tree
+
+	limits TxnLimits
+	ops    int // inserts and deletes performed
+	copied int // nodes copied by inserts and deletes
+
+	consolidated int // copied at the last consolidation
+}
+
+// ChangeOp is the kind of modification recorded in a Change.
+type ChangeOp int
+
+const (
+	ChangeInsert ChangeOp = iota // element inserted or replaced
+	ChangeDelete                 // element deleted
+)
+
+// Change is a modification made by a transaction.
+type Change struct {
+	Op   ChangeOp
+	Elem Element // element inserted, or query matching the deleted one
 }
 
 // Range performs fn on all values stored in the tree over the interval
@@ -39,12 +110,15 @@ type Txn struct {
 // values sort relationships future tree operation behaviors are
 // undefined.
 func (t *Tree) Range(from, to Element, fn Visitor) bool {
-	if t.root == nil {
+	if t == nil || t.root == nil {
 		return false
 	}
 	if from.Compare(to) > 0 {
 		panic("inverted range")
 	}
+	if t.audit {
+		fn = auditVisitor(fn)
+	}
 	return t.root.doRange(from, to, fn)
 }
 
@@ -53,30 +127,59 @@ func (t *Tree) Range(from, to Element, fn Visitor) bool {
 // a Visitor returning true. If fn alters stored values sort
 // relationships, future tree operation behaviors are undefined.
 func (t *Tree) ForEach(fn Visitor) bool {
-	if t.root == nil {
+	if t == nil || t.root == nil {
 		return false
 	}
+	if t.audit {
+		fn = auditVisitor(fn)
+	}
 	return t.root.do(fn)
 }
 
 // Get returns the first match of elem in the Tree. If insertion without
 // replacement is used, this is probably not what you want.
 func (t *Tree) Get(elem Element) Element {
-	if t.root == nil {
+	if t == nil || t.root == nil || !t.bloom.mayContain(elem) {
 		return nil
 	}
-	n := t.root.find(elem)
+	p := t.stats.probe()
+	n := t.root.find(elem, p)
+	t.stats.record(opGet, p)
 	if n == nil {
 		return nil
 	}
 	return n.elem
 }
 
+// Floor returns the largest element in the Tree not greater than elem,
+// or nil if there is no such element.
+func (t *Tree) Floor(elem Element) Element {
+	if t == nil {
+		return nil
+	}
+	if n := t.root.floor(elem); n != nil {
+		return n.elem
+	}
+	return nil
+}
+
+// Ceil returns the smallest element in the Tree not less than elem, or
+// nil if there is no such element.
+func (t *Tree) Ceil(elem Element) Element {
+	if t == nil {
+		return nil
+	}
+	if n := t.root.ceil(elem); n != nil {
+		return n.elem
+	}
+	return nil
+}
+
 // Max returns the maximum value stored in the tree. This will be the
 // right-most maximum value if insertion without replacement has been
 // used.
 func (t *Tree) Max() Element {
-	if t.root == nil {
+	if t == nil || t.root == nil {
 		return nil
 	}
 	return t.root.max().elem
@@ -86,14 +189,36 @@ func (t *Tree) Max() Element {
 // left-most minimum value if insertion without replacement has been
 // used.
 func (t *Tree) Min() Element {
-	if t.root == nil {
+	if t == nil || t.root == nil {
 		return nil
 	}
 	return t.root.min().elem
 }
 
 // Len returns the number of elements stored in the Tree.
-func (t *Tree) Len() int { return t.size }
+func (t *Tree) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.size
+}
+
+// Version returns the number of commits that modified the tree since it
+// was created. Commit returns a tree with the next version number if
+// the transaction modified the tree since it was started or last
+// committed, and with the same number otherwise; operations leaving the
+// elements unchanged, such as deleting a missing element, may still
+// count as modifications. Comparing versions of trees committed in
+// sequence thus tells cheaply whether anything changed. Trees committed
+// by different transactions started on the same version may share a
+// number, and trees derived by other means, such as Compact or Split,
+// keep the version of the tree they were derived from.
+func (t *Tree) Version() uint64 {
+	if t == nil {
+		return 0
+	}
+	return t.version
+}
 
 // Snapshot returns a copy of the underlying tree.
 func (t *Tree) Snapshot() *Tree {
@@ -102,7 +227,7 @@ func (t *Tree) Snapshot() *Tree {
 		return tree
 	}
 
-	tree.size = t.size
+	*tree = *t
 	if t.root != nil {
 		tree.root = t.root.copy()
 	}
@@ -111,17 +236,63 @@ func (t *Tree) Snapshot() *Tree {
 
 // Txn starts a new transaction that can be used to mutate the tree.
 func (t *Tree) Txn() *Txn {
-	return &Txn{tree: t.Snapshot()}
+	txn := &Txn{tree: t.Snapshot()}
+	txn.base = txn.tree.root
+	txn.bloom.begin(txn.tree)
+	if txn.tree.checkTxn {
+		txn.check = &txnCheck{owner: goid()}
+	}
+	if txn.tree.leakf != nil {
+		txn.watchLeak()
+	}
+	txn.track = txn.tree.metaNow != nil
+	return txn
 }
 
-// Commit is used to finalize the transaction and return a new tree
+// Commit is used to finalize the transaction and return a new tree.
+// The transaction remains usable and may be committed again, returning
+// further versions. Trees returned by earlier commits are not affected
+// by later modifications, which continue on a copy.
 func (t *Txn) Commit() *Tree {
-	return t.tree
+	t.enter()
+	defer t.leave()
+
+	if t.tree.root != t.base {
+		t.tree.version++
+	}
+	if t.tree.needsCompaction() || t.needsConsolidation() {
+		t.tree = t.tree.Compact()
+		t.consolidated = t.copied
+	}
+	t.bloom.commit(t.tree)
+	t.stampMeta()
+	t.changes = nil
+	t.unwatchLeak()
+
+	tree := t.tree
+	t.tree = tree.Snapshot()
+	t.base = t.tree.root
+	t.bloom.begin(t.tree)
+	return tree
+}
+
+// Abort discards the modifications made by the transaction since it was
+// started or last committed. The trees it was started on or committed
+// are left unchanged, and the transaction must not be used afterwards.
+func (t *Txn) Abort() {
+	t.own()
+	defer t.leave()
+
+	t.changes, t.appends, t.buffered = nil, nil, nil
+	t.unwatchLeak()
 }
 
 // Get returns the first match of elem in the Tree. If insertion without
 // replacement is used, this is probably not what you want.
 func (t *Txn) Get(elem Element) Element {
+	t.enter()
+	defer t.leave()
+
 	return t.tree.Get(elem)
 }
 
@@ -129,6 +300,9 @@ func (t *Txn) Get(elem Element) Element {
 // right-most maximum value if insertion without replacement has been
 // used.
 func (t *Txn) Max() Element {
+	t.enter()
+	defer t.leave()
+
 	return t.tree.Max()
 }
 
@@ -136,6 +310,9 @@ func (t *Txn) Max() Element {
 // left-most minimum value if insertion without replacement has been
 // used.
 func (t *Txn) Min() Element {
+	t.enter()
+	defer t.leave()
+
 	return t.tree.Min()
 }
 
@@ -146,7 +323,17 @@ func (t *Txn) Min() Element {
 // query Element must be used that can return 0 with a elem.Compare()
 // call.
 func (t *Txn) Insert(elem Element) {
-	root, m := t.tree.root.insert(elem)
+	t.enter()
+	defer t.leave()
+
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	p := t.probe()
+	root, m := t.tree.root.insert(elem, t.tree.aug, p)
+	t.account(opInsert, p)
+	t.bloom.insert(elem)
+	t.record(ChangeInsert, elem)
 	t.tree.size += m
 	t.tree.root = root
 	t.tree.root.color = black
@@ -157,10 +344,23 @@ func (t *Txn) Insert(elem Element) {
 // where non-unique keys are used, attributes used to break ties must be
 // used to determine tree ordering during insertion.
 func (t *Txn) Delete(elem Element) {
+	t.enter()
+	defer t.leave()
+
 	if t.tree == nil || t.tree.root == nil {
 		return
 	}
-	root, m := t.tree.root.delete(elem)
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	p := t.probe()
+	root, m := t.tree.root.delete(elem, p)
+	t.account(opDelete, p)
+	if m != 0 {
+		t.record(ChangeDelete, elem)
+	}
+	t.bloom.delete(m)
+	t.tree.deletes -= m
 	t.tree.size += m
 	t.tree.root = root
 	if root == nil {
@@ -173,10 +373,23 @@ func (t *Txn) Delete(elem Element) {
 // insertion without replacement has been used, the right-most maximum
 // will be deleted.
 func (t *Txn) DeleteMax() {
+	t.enter()
+	defer t.leave()
+
 	if t.tree == nil || t.tree.root == nil {
 		return
 	}
-	root, m := t.tree.root.deleteMax()
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	if t.track {
+		t.record(ChangeDelete, t.tree.root.max().elem)
+	}
+	p := t.probe()
+	root, m := t.tree.root.deleteMax(p)
+	t.account(opDelete, p)
+	t.bloom.delete(m)
+	t.tree.deletes -= m
 	t.tree.size += m
 	t.tree.root = root
 	if root == nil {
@@ -189,10 +402,23 @@ func (t *Txn) DeleteMax() {
 // insertion without replacement has been used, the left-most minimum
 // will be deleted.
 func (t *Txn) DeleteMin() {
+	t.enter()
+	defer t.leave()
+
 	if t.tree == nil || t.tree.root == nil {
 		return
 	}
-	root, m := t.tree.root.deleteMin()
+	if err := t.admit(); err != nil {
+		panic(err)
+	}
+	if t.track {
+		t.record(ChangeDelete, t.tree.root.min().elem)
+	}
+	p := t.probe()
+	root, m := t.tree.root.deleteMin(p)
+	t.account(opDelete, p)
+	t.bloom.delete(m)
+	t.tree.deletes -= m
 	t.tree.size += m
 	t.tree.root = root
 	if root == nil {
@@ -201,5 +427,36 @@ func (t *Txn) DeleteMin() {
 	t.tree.root.color = black
 }
 
+// TrackChanges makes the transaction record its inserts and deletes
+// from now on for Changes. Recording holds on to every inserted and
+// deleted element until the next Commit, so transactions only do it
+// when asked to, or when the tree was created with WithMeta.
+func (t *Txn) TrackChanges() {
+	t.enter()
+	defer t.leave()
+	t.track = true
+}
+
+// record records a change if the transaction tracks changes.
+func (t *Txn) record(op ChangeOp, elem Element) {
+	if t.track {
+		t.changes = append(t.changes, Change{Op: op, Elem: elem})
+	}
+}
+
+// Changes returns the inserts and deletes performed by the transaction
+// since it was started or last committed, in the order they were made,
+// if it tracks changes, or nil otherwise. Deletes that did not find a
+// matching element are not included.
+func (t *Txn) Changes() []Change {
+	t.enter()
+	defer t.leave()
+	return append([]Change(nil), t.changes...)
+}
+
 // Len returns the number of elements stored in the Tree.
-func (t *Txn) Len() int { return t.tree.size }
+func (t *Txn) Len() int {
+	t.enter()
+	defer t.leave()
+	return t.tree.size
+}
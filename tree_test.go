@@ -33,6 +33,13 @@ func (t *Tree) isBalanced() bool {
 	return t.root.isBalanced(black)
 }
 
+func (t *Tree) isSized() bool {
+	if t == nil {
+		return true
+	}
+	return t.root.isSized() && t.root.len() == t.size
+}
+
 func (t *Tree) isBST() bool {
 	if t == nil {
 		return true
@@ -89,6 +96,9 @@ func TestInsertion(t *testing.T) {
 		if !txn.tree.isBalanced() {
 			t.Fatalf("insertion: tree is not balanced")
 		}
+		if !txn.tree.isSized() {
+			t.Fatalf("insertion: subtree sizes are inconsistent")
+		}
 		if !txn.tree.is23() {
 			t.Fatalf("insertion: invariant violation")
 		}
@@ -129,6 +139,9 @@ func TestDeletion(t *testing.T) {
 			if !txn.tree.isBalanced() {
 				t.Fatalf("deletion: tree is not balanced")
 			}
+			if !txn.tree.isSized() {
+				t.Fatalf("deletion: subtree sizes are inconsistent")
+			}
 			if !txn.tree.is23() {
 				t.Fatalf("deletion: invariant violation")
 			}
@@ -213,6 +226,9 @@ func TestRandomInsertionAndDeletion(t *testing.T) {
 		if !tree.isBalanced() {
 			t.Fatalf("random insertion and deletion: tree is not balanced")
 		}
+		if !tree.isSized() {
+			t.Fatalf("random insertion and deletion: subtree sizes are inconsistent")
+		}
 		if !tree.is23() {
 			t.Fatalf("random insertion and deletion: tree is not a 2-3 tree")
 		}
@@ -229,6 +245,9 @@ func TestRandomInsertionAndDeletion(t *testing.T) {
 		if !tree.isBalanced() {
 			t.Fatalf("random insertion and deletion: tree is not balanced")
 		}
+		if !tree.isSized() {
+			t.Fatalf("random insertion and deletion: subtree sizes are inconsistent")
+		}
 		if !tree.is23() {
 			t.Fatalf("random insertion and deletion: tree is not a 2-3 tree")
 		}
@@ -262,6 +281,9 @@ func TestDeleteMinMax(t *testing.T) {
 		if !tree.isBalanced() {
 			t.Fatalf("delete min/max: tree is not balanced")
 		}
+		if !tree.isSized() {
+			t.Fatalf("delete min/max: subtree sizes are inconsistent")
+		}
 		if !tree.is23() {
 			t.Fatalf("delete min/max: tree is not a 2-3 tree")
 		}
@@ -276,8 +298,154 @@ func TestDeleteMinMax(t *testing.T) {
 		if !tree.isBalanced() {
 			t.Fatalf("delete min/max: tree is not balanced")
 		}
+		if !tree.isSized() {
+			t.Fatalf("delete min/max: subtree sizes are inconsistent")
+		}
 		if !tree.is23() {
 			t.Fatalf("delete min/max: tree is not a 2-3 tree")
 		}
 	}
 }
+
+func TestNilTree(t *testing.T) {
+	var tree *Tree
+	if tree.Len() != 0 || tree.Min() != nil || tree.Max() != nil || tree.Get(Int(42)) != nil {
+		t.Fatalf("expected nil tree to behave like an empty tree")
+	}
+	if tree.Range(Int(0), Int(1), nil) || tree.ForEach(nil) {
+		t.Fatalf("expected nil tree traversal not to be interrupted")
+	}
+	if done, err := tree.TryRange(Int(0), Int(1), nil); done || err != nil {
+		t.Fatalf("expected nil tree traversal not to fail, got %v", err)
+	}
+	if tree.Snapshot().Len() != 0 || tree.Freeze().Len() != 0 || tree.Compact().Len() != 0 {
+		t.Fatalf("expected copies of nil tree to be empty")
+	}
+
+	txn := tree.Txn()
+	txn.Insert(Int(42))
+	if tree = txn.Commit(); tree.Get(Int(42)) != Int(42) {
+		t.Fatalf("expected element %v, got %v", Int(42), tree.Get(Int(42)))
+	}
+}
+
+func TestChanges(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	txn.Insert(compInt(-1))
+	if changes := txn.Changes(); changes != nil {
+		t.Fatalf("changes: expected nothing recorded without tracking, have %v", changes)
+	}
+	txn.Delete(compInt(-1))
+	txn.TrackChanges()
+	for i := compInt(0); i < 5; i++ {
+		txn.Insert(i)
+	}
+	txn.Delete(compInt(2))
+	txn.Delete(compInt(42))
+	txn.DeleteMin()
+	txn.DeleteMax()
+
+	want := []Change{
+		{ChangeInsert, compInt(0)},
+		{ChangeInsert, compInt(1)},
+		{ChangeInsert, compInt(2)},
+		{ChangeInsert, compInt(3)},
+		{ChangeInsert, compInt(4)},
+		{ChangeDelete, compInt(2)},
+		{ChangeDelete, compInt(0)},
+		{ChangeDelete, compInt(4)},
+	}
+	if changes := txn.Changes(); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes: expected %v, have %v", want, changes)
+	}
+
+	txn.Commit()
+	if changes := txn.Changes(); len(changes) != 0 {
+		t.Fatalf("changes: expected no pending changes after commit, have %v", changes)
+	}
+}
+
+func TestFloorCeil(t *testing.T) {
+	tree := &Tree{}
+	if tree.Floor(compInt(0)) != nil || tree.Ceil(compInt(0)) != nil {
+		t.Fatalf("floor/ceil: expected <nil> value for empty tree")
+	}
+	txn := tree.Txn()
+	for i := compInt(0); i < 100; i++ {
+		txn.Insert(10 * i)
+	}
+	tree = txn.Commit()
+
+	for i := compInt(-5); i < 1000; i++ {
+		var floor, ceil Element
+		if i >= 0 {
+			floor = i / 10 * 10
+		}
+		if i <= 990 {
+			ceil = (i + 9) / 10 * 10
+			if i < 0 {
+				ceil = compInt(0)
+			}
+		}
+		if tree.Floor(i) != floor {
+			t.Fatalf("floor: expected %v for %v, got %v", floor, i, tree.Floor(i))
+		}
+		if tree.Ceil(i) != ceil {
+			t.Fatalf("ceil: expected %v for %v, got %v", ceil, i, tree.Ceil(i))
+		}
+	}
+}
+
+func TestMultipleCommits(t *testing.T) {
+	tree := New(WithBloom(8))
+	txn := tree.Txn()
+	var versions []*Tree
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 100; j++ {
+			txn.Insert(compInt(100*i + j))
+		}
+		txn.Delete(compInt(100 * i))
+		versions = append(versions, txn.Commit())
+	}
+	for i, v := range versions {
+		if want := 99 * (i + 1); v.Len() != want {
+			t.Fatalf("multiple commits: expected version %d to hold %d elements, have %d", i, want, v.Len())
+		}
+		if v.Get(compInt(100*i+1)) == nil || v.Get(compInt(100*i+101)) != nil {
+			t.Fatalf("multiple commits: version %d holds wrong elements", i)
+		}
+		if !v.isBST() || !v.isBalanced() || !v.is23() || !v.isSized() {
+			t.Fatalf("multiple commits: version %d is not a valid tree", i)
+		}
+	}
+	if txn.Len() != versions[4].Len() {
+		t.Fatalf("multiple commits: expected transaction to continue on last version")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	if v := (*Tree)(nil).Version(); v != 0 {
+		t.Fatalf("version: expected 0 for nil tree, have %d", v)
+	}
+	tree := New()
+	txn := tree.Txn()
+	txn.Insert(compInt(1))
+	v1 := txn.Commit()
+	txn.Insert(compInt(2))
+	v2 := txn.Commit()
+	unchanged := txn.Commit()
+	txn = v2.Txn()
+	txn.DeleteMin()
+	v3 := txn.Commit()
+	if tree.Version() != 0 || v1.Version() != 1 || v2.Version() != 2 || v3.Version() != 3 {
+		t.Fatalf("version: expected 0, 1, 2, 3, have %d, %d, %d, %d",
+			tree.Version(), v1.Version(), v2.Version(), v3.Version())
+	}
+	if v := unchanged.Version(); v != 2 {
+		t.Fatalf("version: expected commit without changes to keep version 2, have %d", v)
+	}
+	if v := v3.Compact().Version(); v != 3 {
+		t.Fatalf("version: expected compacted tree to keep version 3, have %d", v)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "io"
+
+// SnapshotReport describes a snapshot checked by VerifySnapshot.
+type SnapshotReport struct {
+	Elements int     // elements decoded
+	Blocks   int     // blocks read
+	Bytes    int64   // bytes read
+	Min, Max Element // smallest and largest element decoded
+	Err      error   // first problem found, nil if the snapshot is valid
+}
+
+// VerifySnapshot reads a snapshot written by Persist from r and checks
+// its header, block structure and element count, that every element
+// decodes with c, and that the elements are in ascending order. Elements
+// are checked one at a time, so unless load is set the snapshot is never
+// held in memory. If load is set, the tree built from the snapshot by
+// Restore is also checked with Check.
+//
+// Errors in the snapshot wrap ErrSnapshot and give the block and offset
+// they were found at; errors in the loaded tree wrap ErrInvariant.
+func VerifySnapshot(r io.Reader, c Codec, load bool) *SnapshotReport {
+	rep := &SnapshotReport{}
+	sr, err := newSnapshotReader(r, c)
+	if err != nil {
+		rep.Err = err
+		return rep
+	}
+	var elems []Element
+	for {
+		elem, err := sr.next()
+		rep.Blocks, rep.Bytes = sr.blocks, sr.offset
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rep.Err = err
+			return rep
+		}
+		if rep.Min == nil {
+			rep.Min = elem
+		}
+		rep.Max = elem
+		rep.Elements++
+		if load {
+			elems = append(elems, elem)
+		}
+	}
+	if load {
+		rep.Err = fromElements(elems).Check()
+	}
+	return rep
+}
@@ -0,0 +1,43 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestVerifySnapshot(t *testing.T) {
+	tree := randomTree(30000, 100000)
+	var buf bytes.Buffer
+	if err := tree.Persist(&buf, intCodec{}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	good := buf.Bytes()
+
+	for _, load := range []bool{false, true} {
+		rep := VerifySnapshot(bytes.NewReader(good), intCodec{}, load)
+		if rep.Err != nil {
+			t.Fatalf("verify: unexpected error: %v", rep.Err)
+		}
+		if rep.Elements != tree.Len() || rep.Bytes != int64(len(good)) || rep.Blocks < 2 ||
+			rep.Min != tree.Min() || rep.Max != tree.Max() {
+			t.Fatalf("verify: unexpected report %+v", rep)
+		}
+	}
+
+	// Flip a byte in the payload of the last block.
+	bad := append([]byte(nil), good...)
+	bad[len(bad)-10] ^= 1
+	rep := VerifySnapshot(bytes.NewReader(bad), intCodec{}, false)
+	want := fmt.Sprintf("block %d at offset", rep.Blocks-1)
+	if !errors.Is(rep.Err, ErrSnapshot) || !strings.Contains(rep.Err.Error(), want) ||
+		!strings.Contains(rep.Err.Error(), "checksum mismatch") {
+		t.Fatalf("verify: expected checksum error in last block, have %v", rep.Err)
+	}
+}
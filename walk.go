@@ -0,0 +1,83 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// Color is the color of a tree node.
+type Color int
+
+// Node colors of a Left-Leaning Red-Black tree.
+const (
+	Red Color = iota
+	Black
+)
+
+func (c Color) String() string {
+	if c == Red {
+		return "red"
+	}
+	return "black"
+}
+
+// Walk performs fn on all elements of the tree in ascending order,
+// together with the depth of their node, the root being at depth 0, and
+// its color. It exposes the structure of the tree for analysis and
+// visualization. A boolean is returned indicating whether the traversal
+// was interrupted by fn returning true.
+func (t *Tree) Walk(fn func(elem Element, depth int, color Color) (done bool)) bool {
+	if t == nil {
+		return false
+	}
+	return t.root.walk(0, inOrder, fn)
+}
+
+// WalkPreOrder is like Walk but visits each node before the nodes of its
+// left and right subtrees, as needed to rebuild the tree from the
+// sequence of nodes.
+func (t *Tree) WalkPreOrder(fn func(elem Element, depth int, color Color) (done bool)) bool {
+	if t == nil {
+		return false
+	}
+	return t.root.walk(0, preOrder, fn)
+}
+
+// WalkPostOrder is like Walk but visits each node after the nodes of its
+// left and right subtrees, as needed to compute values of subtrees from
+// those of their children.
+func (t *Tree) WalkPostOrder(fn func(elem Element, depth int, color Color) (done bool)) bool {
+	if t == nil {
+		return false
+	}
+	return t.root.walk(0, postOrder, fn)
+}
+
+// Orders in which walk visits a node relative to its subtrees.
+const (
+	inOrder = iota
+	preOrder
+	postOrder
+)
+
+func (n *node) walk(depth, order int, fn func(Element, int, Color) bool) bool {
+	if n == nil {
+		return false
+	}
+	c := Red
+	if n.color == black {
+		c = Black
+	}
+	if order == preOrder && fn(n.elem, depth, c) {
+		return true
+	}
+	if n.left.walk(depth+1, order, fn) {
+		return true
+	}
+	if order == inOrder && fn(n.elem, depth, c) {
+		return true
+	}
+	if n.right.walk(depth+1, order, fn) {
+		return true
+	}
+	return order == postOrder && fn(n.elem, depth, c)
+}
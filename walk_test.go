@@ -0,0 +1,69 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 4; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+
+	var b strings.Builder
+	tree.Walk(func(elem Element, depth int, color Color) bool {
+		fmt.Fprintf(&b, "%v:%d:%v ", elem, depth, color)
+		return false
+	})
+	// 1 is the root, 3 leans left over 2.
+	if want := "0:1:black 1:0:black 2:2:red 3:1:black "; b.String() != want {
+		t.Fatalf("walk: expected %q, have %q", want, b.String())
+	}
+
+	n := 0
+	if !tree.Walk(func(Element, int, Color) bool { n++; return n == 2 }) || n != 2 {
+		t.Fatalf("walk: expected interrupted walk after 2 elements, visited %d", n)
+	}
+}
+
+func TestWalkOrders(t *testing.T) {
+	tree := &Tree{}
+	txn := tree.Txn()
+	for i := compInt(0); i < 4; i++ {
+		txn.Insert(i)
+	}
+	tree = txn.Commit()
+
+	for _, test := range []struct {
+		name string
+		walk func(func(Element, int, Color) bool) bool
+		want string
+	}{
+		{"pre-order", tree.WalkPreOrder, "1:0:black 0:1:black 3:1:black 2:2:red "},
+		{"post-order", tree.WalkPostOrder, "0:1:black 2:2:red 3:1:black 1:0:black "},
+	} {
+		var b strings.Builder
+		test.walk(func(elem Element, depth int, color Color) bool {
+			fmt.Fprintf(&b, "%v:%d:%v ", elem, depth, color)
+			return false
+		})
+		if b.String() != test.want {
+			t.Fatalf("walk %s: expected %q, have %q", test.name, test.want, b.String())
+		}
+		n := 0
+		if !test.walk(func(Element, int, Color) bool { n++; return n == 3 }) || n != 3 {
+			t.Fatalf("walk %s: expected interrupted walk after 3 elements, visited %d", test.name, n)
+		}
+	}
+	if (*Tree)(nil).WalkPreOrder(nil) || (*Tree)(nil).WalkPostOrder(nil) {
+		t.Fatalf("walk: expected nil tree not to be interrupted")
+	}
+}
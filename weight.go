@@ -0,0 +1,87 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import "math/rand"
+
+// Weighted is implemented by elements that carry a weight for
+// SampleWeighted on trees created with WithWeights. Elements that do not
+// implement Weighted have weight 0.
+type Weighted interface {
+	// Weight returns the weight of the element. Negative and NaN
+	// weights count as 0. The weight of a stored element must not
+	// change.
+	Weight() float64
+}
+
+// WithWeights makes every node keep the sum of the weights in its
+// subtree, maintained through inserts and deletes like the subtree
+// size, so that TotalWeight takes constant and SampleWeighted
+// logarithmic time. It costs a Weighted type assertion each time a
+// node is updated.
+func WithWeights() Option {
+	return func(t *Tree) {
+		t.aug |= augWeight
+	}
+}
+
+// weightOf returns the weight of elem.
+func weightOf(elem Element) float64 {
+	if w, ok := elem.(Weighted); ok {
+		if v := w.Weight(); v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// totalWeight returns the sum of the weights of the elements in the
+// subtree rooted at n.
+func (n *node) totalWeight() float64 {
+	if n.kinds()&augWeight == 0 {
+		return 0
+	}
+	return n.aug.weight
+}
+
+// TotalWeight returns the sum of the weights of all elements in the
+// tree, or 0 if it was not created with WithWeights.
+func (t *Tree) TotalWeight() float64 {
+	if t == nil {
+		return 0
+	}
+	return t.root.totalWeight()
+}
+
+// SampleWeighted returns an element chosen at random from rng with
+// probability proportional to its weight, or nil if the total weight of
+// the tree is 0, as it is for trees not created with WithWeights.
+func (t *Tree) SampleWeighted(rng *rand.Rand) Element {
+	total := t.TotalWeight()
+	if total <= 0 {
+		return nil
+	}
+	x := rng.Float64() * total
+
+	var last Element // guards against rounding past the final element
+	for n := t.root; n != nil; {
+		lw := n.left.totalWeight()
+		if x < lw {
+			n = n.left
+			continue
+		}
+		x -= lw
+		w := weightOf(n.elem)
+		if w > 0 {
+			if x < w {
+				return n.elem
+			}
+			last = n.elem
+		}
+		x -= w
+		n = n.right
+	}
+	return last
+}
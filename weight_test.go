@@ -0,0 +1,57 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// weightedInt is an element with weight equal to its value.
+type weightedInt int
+
+func (i weightedInt) Compare(elem Element) int { return int(i) - int(elem.(weightedInt)) }
+func (i weightedInt) Weight() float64          { return float64(i) }
+
+func TestSampleWeighted(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if e := (&Tree{}).SampleWeighted(rng); e != nil {
+		t.Fatalf("sample weighted: expected nil for empty tree, have %v", e)
+	}
+
+	tree := New(WithWeights())
+	txn := tree.Txn()
+	for i := weightedInt(0); i < 100; i++ {
+		txn.Insert(i)
+	}
+	for i := weightedInt(5); i < 100; i++ {
+		txn.Delete(i)
+	}
+	tree = txn.Commit()
+	if w := tree.TotalWeight(); w != 10 {
+		t.Fatalf("sample weighted: expected total weight 10, have %v", w)
+	}
+
+	const samples = 100000
+	counts := make(map[Element]int)
+	for i := 0; i < samples; i++ {
+		counts[tree.SampleWeighted(rng)]++
+	}
+	if counts[weightedInt(0)] != 0 {
+		t.Fatalf("sample weighted: sampled element of weight 0")
+	}
+	for i := weightedInt(1); i < 5; i++ {
+		want := samples * float64(i) / 10
+		if got := float64(counts[i]); math.Abs(got-want) > 0.05*want {
+			t.Fatalf("sample weighted: expected about %v samples of %d, have %v", want, i, got)
+		}
+	}
+	txn = New().Txn()
+	txn.Insert(weightedInt(3))
+	if w := txn.Commit().TotalWeight(); w != 0 {
+		t.Fatalf("sample weighted: expected no weight without WithWeights, have %v", w)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package window implements a sliding-window event counter backed by
+// an immutable llrb tree, as a building block for rate limiting.
+package window
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mars9/llrb"
+)
+
+// event is an occurrence recorded by a Counter.
+type event struct {
+	at  time.Time
+	seq uint64 // orders events recorded at the same time, starting at 1
+}
+
+// Compare implements the llrb.Element interface.
+func (e event) Compare(elem llrb.Element) int {
+	o := elem.(event)
+	if c := e.at.Compare(o.at); c != 0 {
+		return c
+	}
+	switch {
+	case e.seq < o.seq:
+		return -1
+	case e.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+// Time implements the llrb.Timed interface.
+func (e event) Time() time.Time { return e.at }
+
+// Counter counts events within a sliding window of time. It is safe for
+// concurrent use.
+type Counter struct {
+	window time.Duration
+
+	mu     sync.Mutex // serializes modifications
+	seq    uint64
+	events llrb.Atomic
+}
+
+// New returns a Counter that keeps events for the given window.
+func New(window time.Duration) *Counter {
+	return &Counter{window: window}
+}
+
+// Add records an event that occurred at the given time.
+func (c *Counter) Add(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	txn := c.events.Load().Txn()
+	txn.Insert(event{at: at, seq: c.seq})
+	c.events.Store(txn.Commit())
+}
+
+// CountSince returns the number of recorded events at or after t. The
+// cost is logarithmic in the number of events held.
+func (c *Counter) CountSince(t time.Time) int {
+	events := c.events.Load()
+	return events.Len() - events.Rank(event{at: t})
+}
+
+// Prune removes events that fell out of the window ending at now and
+// returns the number of events removed. The cost is logarithmic in the
+// number of events held plus the number removed.
+func (c *Counter) Prune(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	events := c.events.Load()
+	txn := events.Txn()
+	txn.DeleteOlderThan(now.Add(-c.window))
+	n := events.Len() - txn.Len()
+	if n > 0 {
+		c.events.Store(txn.Commit())
+	}
+	return n
+}
+
+// Len returns the number of events held.
+func (c *Counter) Len() int { return c.events.Load().Len() }
+
+// Snapshot returns the events held as an immutable tree, unaffected by
+// later calls to Add and Prune. Elements implement llrb.Timed.
+func (c *Counter) Snapshot() *llrb.Tree { return c.events.Load() }
@@ -0,0 +1,43 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounter(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := New(10 * time.Second)
+	if n := c.CountSince(start); n != 0 {
+		t.Fatalf("counter: expected no events, have %d", n)
+	}
+
+	for i := 0; i < 20; i++ {
+		c.Add(start.Add(time.Duration(i) * time.Second))
+		c.Add(start.Add(time.Duration(i) * time.Second))
+	}
+	if n := c.CountSince(start.Add(15 * time.Second)); n != 10 {
+		t.Fatalf("counter: expected 10 events since 15s, have %d", n)
+	}
+	if n := c.CountSince(start.Add(15*time.Second + 1)); n != 8 {
+		t.Fatalf("counter: expected 8 events after 15s, have %d", n)
+	}
+
+	snap := c.Snapshot()
+	if n := c.Prune(start.Add(20 * time.Second)); n != 20 {
+		t.Fatalf("counter: expected 20 events pruned, have %d", n)
+	}
+	if c.Len() != 20 || snap.Len() != 40 {
+		t.Fatalf("counter: expected 20 events and 40 in snapshot, have %d and %d", c.Len(), snap.Len())
+	}
+	if n := c.CountSince(start); n != 20 {
+		t.Fatalf("counter: expected 20 events, have %d", n)
+	}
+	if n := c.Prune(start.Add(20 * time.Second)); n != 0 {
+		t.Fatalf("counter: expected nothing pruned, have %d", n)
+	}
+}
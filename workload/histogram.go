@@ -0,0 +1,57 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workload
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Histogram counts latencies in buckets of powers of two nanoseconds.
+// The zero Histogram is empty.
+type Histogram struct {
+	buckets [64]uint64 // buckets[i] counts latencies below 2^i ns
+	count   uint64
+}
+
+// Observe records latency d.
+func (h *Histogram) Observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.buckets[bits.Len64(uint64(d))]++
+	h.count++
+}
+
+// Merge adds the latencies recorded by o to h.
+func (h *Histogram) Merge(o *Histogram) {
+	for i, n := range o.buckets {
+		h.buckets[i] += n
+	}
+	h.count += o.count
+}
+
+// Count returns the number of latencies recorded.
+func (h *Histogram) Count() int { return int(h.count) }
+
+// Percentile returns an upper bound of the p-th percentile of the
+// recorded latencies, within a factor of two, or 0 if none have been
+// recorded.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(p / 100 * float64(h.count))
+	if rank >= h.count {
+		rank = h.count - 1
+	}
+	var seen uint64
+	for i, n := range h.buckets {
+		if seen += n; seen > rank {
+			return time.Duration(1<<uint(i)) - 1
+		}
+	}
+	return time.Duration(1<<63 - 1)
+}
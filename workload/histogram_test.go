@@ -0,0 +1,35 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workload
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	if h.Percentile(50) != 0 {
+		t.Fatalf("histogram: expected 0 for empty histogram")
+	}
+	for i := 1; i <= 100; i++ {
+		h.Observe(time.Duration(i) * time.Microsecond)
+	}
+	for _, p := range []float64{0, 50, 99, 100} {
+		want := time.Duration(p+1) * time.Microsecond
+		if p == 100 {
+			want = 100 * time.Microsecond
+		}
+		if have := h.Percentile(p); have < want || have > 2*want {
+			t.Fatalf("histogram: expected p%v within [%v, %v], have %v", p, want, 2*want, have)
+		}
+	}
+	var m Histogram
+	m.Merge(&h)
+	m.Merge(&h)
+	if m.Count() != 200 || m.Percentile(50) != h.Percentile(50) {
+		t.Fatalf("histogram: unexpected merge result")
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package workload generates YCSB-style workloads against llrb trees,
+// so configuration choices such as tree options, worker counts and
+// commit batch sizes can be compared on throughput and latency.
+package workload
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mars9/llrb"
+)
+
+// Key is the element type of the trees driven by Run.
+type Key int64
+
+// Compare implements the llrb.Element interface.
+func (k Key) Compare(elem llrb.Element) int {
+	switch o := elem.(Key); {
+	case k < o:
+		return -1
+	case k > o:
+		return 1
+	}
+	return 0
+}
+
+// Op is the kind of an operation of a workload.
+type Op int
+
+// Operation kinds.
+const (
+	Read   Op = iota // Get of an existing key
+	Update           // Insert replacing an existing key
+	Insert           // Insert of a new key
+	Scan             // Range over consecutive keys from an existing key
+	numOps
+)
+
+func (op Op) String() string {
+	return [...]string{"read", "update", "insert", "scan"}[op]
+}
+
+// Distribution selects how existing keys are chosen.
+type Distribution int
+
+// Key distributions.
+const (
+	Uniform Distribution = iota // all keys equally likely
+	Zipfian                     // few keys much more likely than the rest
+)
+
+// Config describes a workload. The proportions of the operations need
+// not sum to 1; they are normalized.
+type Config struct {
+	Records int // keys loaded before the run
+	Ops     int // operations run, split among the workers
+
+	Read, Update, Insert, Scan float64 // proportions of the operations
+	ScanLength                 int     // keys visited by a scan, 0 means 100
+
+	Distribution Distribution
+	ZipfS        float64 // Zipfian exponent, greater than 1; 0 means 1.1
+
+	Workers int // goroutines issuing operations, 0 means 1
+	Batch   int // largest number of writes per commit, 0 for no limit
+	Seed    int64
+
+	Opts []llrb.Option // options of the tree
+}
+
+// Result holds the measurements of a run.
+type Result struct {
+	Ops      int           // operations completed
+	Duration time.Duration // wall time of the run, excluding the load
+	Latency  [numOps]Histogram
+}
+
+// Throughput returns the operations completed per second.
+func (r *Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Ops) / r.Duration.Seconds()
+}
+
+// String summarizes the result, one line per operation kind.
+func (r *Result) String() string {
+	s := fmt.Sprintf("%d ops in %v (%.0f ops/s)\n", r.Ops, r.Duration, r.Throughput())
+	for op := Op(0); op < numOps; op++ {
+		h := &r.Latency[op]
+		if h.Count() == 0 {
+			continue
+		}
+		s += fmt.Sprintf("%-6s %8d ops  p50 %-10v p99 %-10v\n", op, h.Count(), h.Percentile(50), h.Percentile(99))
+	}
+	return s
+}
+
+// Run loads cfg.Records keys into a new tree and runs the workload
+// against it. Reads and scans load the current version from an
+// llrb.Atomic without locking, while writes are submitted to an
+// llrb.Committer, which commits up to cfg.Batch of them at once.
+func Run(cfg Config) *Result {
+	if cfg.ScanLength <= 0 {
+		cfg.ScanLength = 100
+	}
+	if cfg.ZipfS <= 1 {
+		cfg.ZipfS = 1.1
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	mix := []float64{cfg.Read, cfg.Update, cfg.Insert, cfg.Scan}
+	var total float64
+	for _, p := range mix {
+		total += p
+	}
+	if total <= 0 {
+		panic("workload: no operations in mix")
+	}
+
+	root := llrb.NewAtomic(load(cfg))
+	c := llrb.NewCommitter(root, cfg.Batch)
+	defer c.Close()
+
+	var (
+		next atomic.Int64 // next new key
+		mu   sync.Mutex   // guards res
+		wg   sync.WaitGroup
+	)
+	res := &Result{}
+	next.Store(int64(cfg.Records))
+	start := time.Now()
+	for w := 0; w < cfg.Workers; w++ {
+		n := cfg.Ops / cfg.Workers
+		if w < cfg.Ops%cfg.Workers {
+			n++
+		}
+		wg.Add(1)
+		go func(seed int64, n int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			var zipf *rand.Zipf
+			var lat [numOps]Histogram
+			for i := 0; i < n; i++ {
+				op := pick(rng, mix, total)
+				var k Key
+				if op != Insert {
+					max := next.Load()
+					if max == 0 {
+						op = Insert
+					} else if cfg.Distribution == Zipfian {
+						if zipf == nil {
+							zipf = rand.NewZipf(rng, cfg.ZipfS, 1, uint64(cfg.Records+cfg.Ops))
+						}
+						k = Key(int64(zipf.Uint64()) % max)
+					} else {
+						k = Key(rng.Int63n(max))
+					}
+				}
+				t := time.Now()
+				switch op {
+				case Read:
+					root.Load().Get(k)
+				case Update:
+					c.Submit(func(txn *llrb.Txn) error { txn.Insert(k); return nil })
+				case Insert:
+					k = Key(next.Add(1) - 1)
+					c.Submit(func(txn *llrb.Txn) error { txn.Insert(k); return nil })
+				case Scan:
+					root.Load().Query().From(k).Limit(cfg.ScanLength).Each(func(llrb.Element) bool { return false })
+				}
+				lat[op].Observe(time.Since(t))
+			}
+			mu.Lock()
+			for op := range lat {
+				res.Latency[op].Merge(&lat[op])
+			}
+			res.Ops += n
+			mu.Unlock()
+		}(cfg.Seed+int64(w), n)
+	}
+	wg.Wait()
+	res.Duration = time.Since(start)
+	return res
+}
+
+// load returns a tree configured by cfg.Opts holding the keys below
+// cfg.Records.
+func load(cfg Config) *llrb.Tree {
+	txn := llrb.New(cfg.Opts...).Txn()
+	for i := 0; i < cfg.Records; i++ {
+		txn.Append(Key(i))
+	}
+	return txn.Commit()
+}
+
+// pick returns an operation chosen by the proportions of mix.
+func pick(rng *rand.Rand, mix []float64, total float64) Op {
+	x := rng.Float64() * total
+	for op, p := range mix {
+		if x < p {
+			return Op(op)
+		}
+		x -= p
+	}
+	return Op(len(mix) - 1)
+}
@@ -0,0 +1,45 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package workload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mars9/llrb"
+)
+
+func TestRun(t *testing.T) {
+	for _, cfg := range []Config{
+		{Records: 1000, Ops: 2000, Read: 0.5, Update: 0.5},
+		{Records: 1000, Ops: 2000, Read: 0.95, Insert: 0.05, Distribution: Zipfian, Workers: 4, Batch: 8},
+		{Ops: 500, Insert: 1, Scan: 1, ScanLength: 10, Opts: []llrb.Option{llrb.WithStats()}},
+	} {
+		res := Run(cfg)
+		if res.Ops != cfg.Ops || res.Duration <= 0 || res.Throughput() <= 0 {
+			t.Fatalf("run: unexpected result %+v", res)
+		}
+		var n int
+		for op := range res.Latency {
+			n += res.Latency[op].Count()
+		}
+		if n != cfg.Ops {
+			t.Fatalf("run: expected %d latencies, have %d", cfg.Ops, n)
+		}
+		if cfg.Update == 0 && res.Latency[Update].Count() != 0 {
+			t.Fatalf("run: unexpected updates")
+		}
+		if !strings.Contains(res.String(), "ops/s") {
+			t.Fatalf("run: unexpected summary %q", res.String())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("run: expected panic for empty mix")
+		}
+	}()
+	Run(Config{Ops: 1})
+}
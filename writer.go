@@ -0,0 +1,49 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// A Writer is a goroutine owning all writes to the tree held by an
+// Atomic. Mutation functions sent on its channel are applied in order,
+// each in a transaction of its own, and every committed version is
+// published before the next function runs.
+type Writer struct {
+	ops  chan func(*Txn) error
+	done chan struct{}
+}
+
+// NewWriter starts a Writer publishing to root. At most queue functions
+// wait to be applied; further sends block until the writer catches up.
+// If onError is not nil it is called from the writer goroutine with the
+// error returned by a failing function, or an error describing its
+// panic. The modifications of a failing function are discarded.
+func NewWriter(root *Atomic, queue int, onError func(error)) *Writer {
+	w := &Writer{
+		ops:  make(chan func(*Txn) error, queue),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(w.done)
+		for fn := range w.ops {
+			txn := root.Load().Txn()
+			if err := apply(txn, fn); err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			root.Store(txn.Commit())
+		}
+	}()
+	return w
+}
+
+// Ops returns the channel mutation functions are sent on. Closing the
+// channel shuts the writer down after all queued functions have been
+// applied.
+func (w *Writer) Ops() chan<- func(*Txn) error { return w.ops }
+
+// Wait blocks until the channel returned by Ops has been closed and all
+// functions sent on it have been applied.
+func (w *Writer) Wait() { <-w.done }
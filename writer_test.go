@@ -0,0 +1,50 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	root := &Atomic{}
+	var errs []error
+	w := NewWriter(root, 4, func(err error) { errs = append(errs, err) })
+
+	var versions []*Tree
+	for i := compInt(0); i < 100; i++ {
+		i := i
+		w.Ops() <- func(txn *Txn) error {
+			versions = append(versions, root.Load())
+			txn.Insert(i)
+			return nil
+		}
+	}
+	errFailed := errors.New("failed")
+	w.Ops() <- func(txn *Txn) error {
+		txn.DeleteMin()
+		return errFailed
+	}
+	w.Ops() <- func(txn *Txn) error {
+		txn.DeleteMin()
+		panic("boom")
+	}
+	close(w.Ops())
+	w.Wait()
+
+	if len(errs) != 2 || errs[0] != errFailed {
+		t.Fatalf("writer: unexpected errors %v", errs)
+	}
+	tree := root.Load()
+	if tree.Len() != 100 || tree.Min() != compInt(0) {
+		t.Fatalf("writer: unexpected tree of length %d", tree.Len())
+	}
+	for i, v := range versions {
+		if v.Len() != i {
+			t.Fatalf("writer: expected version %d to hold %d elements, have %d", i, i, v.Len())
+		}
+	}
+}
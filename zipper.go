@@ -0,0 +1,228 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// A Zipper is focused on a position in one version of a tree and
+// records the path from the root to it. It can be moved to neighbouring
+// elements and used to edit the tree at its focus: the edits return a
+// new tree built by copying the recorded path bottom up, without
+// descending from the root again. The zipper keeps referring to the
+// version it was created from.
+type Zipper struct {
+	tree *Tree
+	path []*node // root to focus, empty if past the largest element
+}
+
+// Zipper returns a Zipper focused on the first element not less than
+// elem, or past the largest element if there is none. A nil elem
+// focuses it on the smallest element.
+func (t *Tree) Zipper(elem Element) *Zipper {
+	z := &Zipper{tree: t}
+	var root *node
+	if t != nil {
+		root = t.root
+	}
+	// Descend to the ceiling of elem, keeping the path to the last node
+	// the descent turned left at, which is the ceiling.
+	keep := 0
+	for n := root; n != nil; {
+		z.path = append(z.path, n)
+		if elem == nil || elem.Compare(n.elem) <= 0 {
+			keep = len(z.path)
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	z.path = z.path[:keep]
+	return z
+}
+
+// Elem returns the element in focus, or nil if the zipper is past the
+// largest element.
+func (z *Zipper) Elem() Element {
+	if len(z.path) == 0 {
+		return nil
+	}
+	return z.path[len(z.path)-1].elem
+}
+
+// Next moves the focus to the next element and reports whether there is
+// one. If there is not, the zipper is left past the largest element.
+func (z *Zipper) Next() bool {
+	if len(z.path) == 0 {
+		return false
+	}
+	if n := z.path[len(z.path)-1]; n.right != nil {
+		for n = n.right; n != nil; n = n.left {
+			z.path = append(z.path, n)
+		}
+		return true
+	}
+	for len(z.path) > 1 {
+		child := z.path[len(z.path)-1]
+		z.path = z.path[:len(z.path)-1]
+		if z.path[len(z.path)-1].left == child {
+			return true
+		}
+	}
+	z.path = z.path[:0]
+	return false
+}
+
+// Prev moves the focus to the previous element and reports whether there
+// is one. If there is not, the focus is left unchanged. Moving back from
+// past the largest element focuses the largest one.
+func (z *Zipper) Prev() bool {
+	if len(z.path) == 0 {
+		if z.tree == nil || z.tree.root == nil {
+			return false
+		}
+		for n := z.tree.root; n != nil; n = n.right {
+			z.path = append(z.path, n)
+		}
+		return true
+	}
+	if n := z.path[len(z.path)-1]; n.left != nil {
+		for n = n.left; n != nil; n = n.right {
+			z.path = append(z.path, n)
+		}
+		return true
+	}
+	for i := len(z.path) - 1; i > 0; i-- {
+		if z.path[i-1].right == z.path[i] {
+			z.path = z.path[:i]
+			return true
+		}
+	}
+	return false
+}
+
+// Replace returns a new tree with the element in focus replaced by
+// elem, which must compare equal to it. Replace panics if the zipper is
+// past the largest element or elem does not compare equal.
+func (z *Zipper) Replace(elem Element) *Tree {
+	if len(z.path) == 0 || elem.Compare(z.Elem()) != 0 {
+		panic("llrb: replacement does not match focus")
+	}
+	leaf := z.path[len(z.path)-1].copy()
+	leaf.elem = elem
+	leaf.update()
+	return z.tree.derive(rebuild(z.path, leaf, false))
+}
+
+// InsertBefore returns a new tree with elem inserted between the
+// element in focus and its predecessor, or after the largest element if
+// the zipper is past it. InsertBefore panics if elem does not lie
+// strictly between the two.
+func (z *Zipper) InsertBefore(elem Element) *Tree {
+	path := append([]*node(nil), z.path...)
+	if len(path) == 0 {
+		if z.tree != nil {
+			for n := z.tree.root; n != nil; n = n.right {
+				path = append(path, n)
+			}
+		}
+		var prev Element
+		if len(path) > 0 {
+			prev = path[len(path)-1].elem
+		}
+		return z.insert(path, false, prev, nil, elem)
+	}
+	focus := path[len(path)-1]
+	if focus.left == nil {
+		var prev Element
+		if p := z.neighbour(false); p != nil {
+			prev = p.elem
+		}
+		return z.insert(path, true, prev, focus.elem, elem)
+	}
+	for n := focus.left; n != nil; n = n.right {
+		path = append(path, n)
+	}
+	return z.insert(path, false, path[len(path)-1].elem, focus.elem, elem)
+}
+
+// InsertAfter returns a new tree with elem inserted between the element
+// in focus and its successor. InsertAfter panics if the zipper is past
+// the largest element or elem does not lie strictly between the two.
+func (z *Zipper) InsertAfter(elem Element) *Tree {
+	if len(z.path) == 0 {
+		panic("llrb: insertion after end")
+	}
+	path := append([]*node(nil), z.path...)
+	focus := path[len(path)-1]
+	if focus.right == nil {
+		var next Element
+		if s := z.neighbour(true); s != nil {
+			next = s.elem
+		}
+		return z.insert(path, false, focus.elem, next, elem)
+	}
+	for n := focus.right; n != nil; n = n.left {
+		path = append(path, n)
+	}
+	return z.insert(path, true, focus.elem, path[len(path)-1].elem, elem)
+}
+
+// neighbour returns the nearest ancestor of the focus holding it in its
+// left subtree if after is set, or in its right subtree otherwise, or
+// nil. If the focus has no subtree on that side, this is its successor
+// or predecessor.
+func (z *Zipper) neighbour(after bool) *node {
+	for i := len(z.path) - 1; i > 0; i-- {
+		p := z.path[i-1]
+		if (after && p.left == z.path[i]) || (!after && p.right == z.path[i]) {
+			return p
+		}
+	}
+	return nil
+}
+
+// insert returns a new tree with elem added as a red leaf below the
+// last node of path, on its left if left is set, after checking that
+// elem lies strictly between prev and next, either of which may be nil.
+func (z *Zipper) insert(path []*node, left bool, prev, next, elem Element) *Tree {
+	if (prev != nil && elem.Compare(prev) <= 0) || (next != nil && elem.Compare(next) >= 0) {
+		panic("llrb: insertion violates order")
+	}
+	leaf := newNode(elem, z.tree.kinds())
+	if len(path) == 0 {
+		leaf.color = black
+		return z.tree.derive(leaf)
+	}
+	n := path[len(path)-1].copy()
+	if left {
+		n.left = leaf
+	} else {
+		n.right = leaf
+	}
+	n.update()
+	return z.tree.derive(rebuild(path, n.fixInsert(), true))
+}
+
+// rebuild returns the root of a tree in which the last node of path is
+// replaced by n, copying its ancestors. If fix is set, the left-leaning
+// invariants are restored at each ancestor as after an insertion.
+func rebuild(path []*node, n *node, fix bool) *node {
+	for i := len(path) - 2; i >= 0; i-- {
+		p := path[i].copy()
+		if p.left == path[i+1] {
+			p.left = n
+		} else {
+			p.right = n
+		}
+		p.update()
+		if fix {
+			p = p.fixInsert()
+		}
+		n = p
+	}
+	if n.isRed() {
+		n = n.copy()
+		n.color = black
+	}
+	return n
+}
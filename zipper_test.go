@@ -0,0 +1,102 @@
+// Copyright ©2016 Markus Sonderegger. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestZipperMove(t *testing.T) {
+	tree := randomTree(200, 1000)
+	want := elements(tree)
+
+	var have []Element
+	z := tree.Zipper(nil)
+	for ok := z.Elem() != nil; ok; ok = z.Next() {
+		have = append(have, z.Elem())
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("zipper next: expected %v, have %v", want, have)
+	}
+	if z.Elem() != nil || z.Next() {
+		t.Fatalf("zipper next: expected zipper past the end")
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		if !z.Prev() || z.Elem() != want[i] {
+			t.Fatalf("zipper prev: expected %v, have %v", want[i], z.Elem())
+		}
+	}
+	if z.Prev() || z.Elem() != want[0] {
+		t.Fatalf("zipper prev: expected to stay at %v, have %v", want[0], z.Elem())
+	}
+
+	for _, e := range []compInt{-1, 0, 500, 999, 1000} {
+		if z, c := tree.Zipper(e), tree.Ceil(e); z.Elem() != c {
+			t.Fatalf("zipper %v: expected focus %v, have %v", e, c, z.Elem())
+		}
+	}
+	if z := (*Tree)(nil).Zipper(nil); z.Elem() != nil || z.Next() || z.Prev() {
+		t.Fatalf("zipper: expected empty zipper for nil tree")
+	}
+}
+
+func TestZipperEdit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tree := &Tree{}
+	for i := 0; i < 500; i++ {
+		v := compInt(rng.Intn(2000))
+		if tree.Get(v) != nil {
+			continue
+		}
+		z := tree.Zipper(v)
+		var next *Tree
+		if rng.Intn(2) == 0 || z.Elem() == nil {
+			next = z.InsertBefore(v)
+		} else {
+			z.Prev()
+			if z.Elem().(compInt) > v {
+				next = z.InsertBefore(v)
+			} else {
+				next = z.InsertAfter(v)
+			}
+		}
+		if err := next.Check(); err != nil {
+			t.Fatalf("zipper insert %v: %v", v, err)
+		}
+		if next.Get(v) == nil || next.Len() != tree.Len()+1 {
+			t.Fatalf("zipper insert %v: element missing", v)
+		}
+		tree = next
+	}
+
+	before := elements(tree)
+	z := tree.Zipper(tree.Max())
+	edited := z.Replace(tree.Max())
+	if err := edited.Check(); err != nil {
+		t.Fatalf("zipper replace: %v", err)
+	}
+	if !reflect.DeepEqual(elements(edited), before) || !reflect.DeepEqual(elements(tree), before) {
+		t.Fatalf("zipper replace: elements changed")
+	}
+
+	for name, edit := range map[string]func(){
+		"replace mismatch": func() { tree.Zipper(tree.Min()).Replace(tree.Max()) },
+		"replace end":      func() { tree.Zipper(compInt(5000)).Replace(compInt(5000)) },
+		"before order":     func() { tree.Zipper(tree.Max()).InsertBefore(compInt(5000)) },
+		"after order":      func() { tree.Zipper(tree.Min()).InsertAfter(compInt(-1)) },
+		"after end":        func() { tree.Zipper(compInt(5000)).InsertAfter(compInt(5000)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("zipper %s: expected panic", name)
+				}
+			}()
+			edit()
+		}()
+	}
+}